@@ -1,14 +1,257 @@
 package config
 
-import "os"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VaultDir is the directory inside a vault that holds its settings.
+const VaultDir = ".notes"
+
+// ConfigFile is the name of the config file inside VaultDir and the
+// user-level config directory.
+const ConfigFile = "config.yaml"
 
 type Config struct {
-	BaseDir string
+	BaseDir string `yaml:"-"`
+
+	Directories []string         `yaml:"directories"`
+	SearchDirs  []string         `yaml:"search_dirs"`
+	Editor      string           `yaml:"editor"`
+	User        UserConfig       `yaml:"user"`
+	Preview     PreviewConfig    `yaml:"preview"`
+	Git         GitConfig        `yaml:"git"`
+	Priority    PriorityConfig   `yaml:"priority"`
+	Time        TimeConfig       `yaml:"time"`
+	Templates   TemplatesConfig  `yaml:"templates"`
+	NoteTypes   []NoteTypeConfig `yaml:"note_types"`
+}
+
+type UserConfig struct {
+	Name   string `yaml:"name"`
+	Handle string `yaml:"handle"`
+}
+
+type PreviewConfig struct {
+	Port int `yaml:"port"`
+}
+
+type GitConfig struct {
+	AutoCommit bool `yaml:"auto_commit"`
+}
+
+// PriorityConfig sets the keywords used to guess a task's priority when it
+// has no explicit marker. Guessing is off unless InferKeywords is set.
+type PriorityConfig struct {
+	InferKeywords bool     `yaml:"infer_keywords"`
+	High          []string `yaml:"high"`
+	Medium        []string `yaml:"medium"`
+}
+
+// TimeConfig sets how time tracking writes time logs. Precise entries give
+// start and end to the second, with the UTC offset.
+type TimeConfig struct {
+	Precise bool `yaml:"precise"`
 }
 
-func New() *Config {
-	wd, _ := os.Getwd()
+type TemplatesConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// Default returns the settings used when no config file overrides them.
+func Default() *Config {
 	return &Config{
-		BaseDir: wd,
+		Directories: []string{"daily", "projects", "meetings", "design", "learning", "todos", "archive"},
+		SearchDirs:  []string{"daily", "projects", "meetings", "design", "learning", "todos"},
+		Preview:     PreviewConfig{Port: 8080},
+		Git:         GitConfig{AutoCommit: true},
+		Priority: PriorityConfig{
+			High:   []string{"urgent", "asap", "critical", "important", "!!!"},
+			Medium: []string{"!!", "soon", "priority"},
+		},
+		Templates: TemplatesConfig{Dir: "templates"},
+		NoteTypes: defaultNoteTypes(),
+	}
+}
+
+// New loads the config for the vault at baseDir, layering the user config
+// and then the vault config over the defaults.
+func New(baseDir string) (*Config, error) {
+	cfg := Default()
+	cfg.BaseDir = baseDir
+
+	for _, path := range cfg.layers() {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// layers returns the config files read over the defaults, in order: the
+// user's, then the vault's, which wins.
+func (c *Config) layers() []string {
+	var paths []string
+	for _, path := range []string{UserConfigPath(), c.VaultConfigPath()} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// VaultConfigPath returns the path of the vault's checked-in config file.
+func (c *Config) VaultConfigPath() string {
+	return filepath.Join(c.BaseDir, VaultDir, ConfigFile)
+}
+
+// UserConfigPath returns the user-level config file, honoring
+// XDG_CONFIG_HOME and falling back to ~/.config.
+func UserConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "notes", ConfigFile)
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
 	}
-}
\ No newline at end of file
+
+	if err := decodeStrict(data, c); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return nil
+}
+
+func decodeStrict(data []byte, out interface{}) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(out)
+}
+
+// Validate reports every invalid setting at once so a broken config can be
+// fixed in a single pass.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Preview.Port < 1 || c.Preview.Port > 65535 {
+		errs = append(errs, fmt.Errorf("preview.port must be between 1 and 65535, got %d", c.Preview.Port))
+	}
+	if len(c.Directories) == 0 {
+		errs = append(errs, fmt.Errorf("directories must list at least one folder"))
+	}
+	for _, dir := range c.Directories {
+		if err := validateRelDir(dir); err != nil {
+			errs = append(errs, fmt.Errorf("directories: %w", err))
+		}
+	}
+	for _, dir := range c.SearchDirs {
+		if err := validateRelDir(dir); err != nil {
+			errs = append(errs, fmt.Errorf("search_dirs: %w", err))
+		}
+	}
+	if err := validateRelDir(c.Templates.Dir); err != nil {
+		errs = append(errs, fmt.Errorf("templates.dir: %w", err))
+	}
+	if len(c.NoteTypes) == 0 {
+		errs = append(errs, fmt.Errorf("note_types must declare at least one type"))
+	}
+	seen := make(map[string]bool)
+	for _, nt := range c.NoteTypes {
+		if seen[nt.Name] {
+			errs = append(errs, fmt.Errorf("note_types: %q is declared more than once", nt.Name))
+		}
+		seen[nt.Name] = true
+		errs = append(errs, nt.validate()...)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validateRelDir(dir string) error {
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("folder name must not be empty")
+	}
+	if filepath.IsAbs(dir) {
+		return fmt.Errorf("%q must be relative to the vault", dir)
+	}
+	if strings.HasPrefix(filepath.Clean(dir), "..") {
+		return fmt.Errorf("%q must stay inside the vault", dir)
+	}
+	return nil
+}
+
+// vaultSettings holds the settings notes init writes into a new vault: the
+// ones everyone sharing the vault should agree on. Personal settings such
+// as the editor are left out so they fall through to the user config.
+type vaultSettings struct {
+	Directories []string         `yaml:"directories"`
+	SearchDirs  []string         `yaml:"search_dirs"`
+	Git         GitConfig        `yaml:"git"`
+	Priority    PriorityConfig   `yaml:"priority"`
+	Templates   TemplatesConfig  `yaml:"templates"`
+	NoteTypes   []NoteTypeConfig `yaml:"note_types"`
+}
+
+// WriteDefault writes a starter vault config if one does not exist yet.
+// It reports whether a file was created.
+func (c *Config) WriteDefault() (bool, error) {
+	path := c.VaultConfigPath()
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", VaultDir, err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Vault settings for notes. Run 'notes config list' to see every key.\n")
+	buf.WriteString("# Personal settings (editor, user, preview, time) belong in your user\n")
+	buf.WriteString("# config; set them with 'notes config set --global <key> <value>'.\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	settings := vaultSettings{
+		Directories: c.Directories,
+		SearchDirs:  c.SearchDirs,
+		Git:         c.Git,
+		Priority:    c.Priority,
+		Templates:   c.Templates,
+		NoteTypes:   c.NoteTypes,
+	}
+	if err := encoder.Encode(settings); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return false, fmt.Errorf("failed to write config: %w", err)
+	}
+	return true, nil
+}
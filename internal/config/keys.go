@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Setting is a single dotted config key and its effective value.
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// List returns every setting in the effective config, sorted by key.
+func (c *Config) List() ([]Setting, error) {
+	values, err := flatten(c)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		settings = append(settings, Setting{Key: key, Value: formatValue(value)})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings, nil
+}
+
+// Get returns the effective value for a dotted key such as "preview.port".
+func (c *Config) Get(key string) (string, error) {
+	values, err := flatten(c)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", unknownKeyError(key)
+	}
+	return formatValue(value), nil
+}
+
+// Set writes key=value into the config file at path, one of the layers of
+// c, keeping any comments already in the file. The config that results
+// from all the layers is validated before anything is written.
+func (c *Config) Set(path, key, value string) error {
+	defaults, err := flatten(Default())
+	if err != nil {
+		return err
+	}
+	defaultValue, ok := defaults[key]
+	if !ok {
+		return unknownKeyError(key)
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	valueNode, err := parseValue(value, defaultValue)
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	if err := setNode(doc.Content[0], strings.Split(key, "."), valueNode); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+
+	// Check the config as New will load it, with the other layer too
+	check := Default()
+	check.BaseDir = c.BaseDir
+	for _, layer := range c.layers() {
+		if layer != path {
+			if err := check.loadFile(layer); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeStrict(buf.Bytes(), check); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+	}
+	if err := check.Validate(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("unknown config key: %s (run 'notes config list' to see available keys)", key)
+}
+
+// parseValue turns a command-line value into a YAML node. Keys whose default
+// is a list also accept a plain comma-separated value.
+func parseValue(value string, defaultValue interface{}) (*yaml.Node, error) {
+	if _, isList := defaultValue.([]interface{}); isList && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: item})
+		}
+		return node, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	}
+	return doc.Content[0], nil
+}
+
+func setNode(mapping *yaml.Node, path []string, value *yaml.Node) error {
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("config key %s is not a section", path[0])
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			mapping.Content[i+1] = value
+			return nil
+		}
+		return setNode(mapping.Content[i+1], path[1:], value)
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Value: path[0]}
+	if len(path) == 1 {
+		mapping.Content = append(mapping.Content, key, value)
+		return nil
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content, key, child)
+	return setNode(child, path[1:], value)
+}
+
+// flatten maps every leaf of the config to its dotted key.
+func flatten(c *Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	var walk func(prefix string, node map[string]interface{})
+	walk = func(prefix string, node map[string]interface{}) {
+		for key, value := range node {
+			if child, ok := value.(map[string]interface{}); ok {
+				walk(prefix+key+".", child)
+				continue
+			}
+			values[prefix+key] = value
+		}
+	}
+	walk("", tree)
+	return values, nil
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fields := make([]string, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, fmt.Sprintf("%s: %s", key, formatValue(v[key])))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestConfig returns the config of an empty vault, with the user config
+// in a fresh XDG_CONFIG_HOME
+func newTestConfig(t *testing.T) *Config {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg, err := New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := newTestConfig(t)
+	writeConfig(t, cfg.VaultConfigPath(), "# Shared settings\ngit:\n  auto_commit: false # no commits\n")
+
+	if err := cfg.Set(cfg.VaultConfigPath(), "preview.port", "4000"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	data, err := os.ReadFile(cfg.VaultConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Shared settings", "# no commits", "port: 4000"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("config file lacks %q:\n%s", want, data)
+		}
+	}
+
+	loaded, err := New(cfg.BaseDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Preview.Port != 4000 || loaded.Git.AutoCommit {
+		t.Errorf("loaded port %d, auto_commit %v; want 4000, false", loaded.Preview.Port, loaded.Git.AutoCommit)
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	cfg := newTestConfig(t)
+	for _, tt := range []struct{ key, value string }{
+		{"preview.port", "0"},
+		{"preview.port", "soon"},
+		{"no.such.key", "1"},
+		{"directories", "../outside"},
+		{"note_types", `[{name: adr, dir: adr, filename: "../{{slug}}", title_required: true}]`},
+		{"note_types", `[{name: adr, dir: adr, filename: "adr/{{slug}}", title_required: true}]`},
+	} {
+		if err := cfg.Set(cfg.VaultConfigPath(), tt.key, tt.value); err == nil {
+			t.Errorf("Set(%s, %s): want an error", tt.key, tt.value)
+		}
+	}
+	if _, err := os.Stat(cfg.VaultConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("an invalid Set wrote the config file")
+	}
+}
+
+func TestSetChecksOtherLayer(t *testing.T) {
+	cfg := newTestConfig(t)
+	// The user config broke after the vault config was loaded
+	writeConfig(t, UserConfigPath(), "preview:\n  port: 70000\n")
+
+	if err := cfg.Set(cfg.VaultConfigPath(), "git.auto_commit", "false"); err == nil {
+		t.Errorf("Set with an invalid user config: want an error")
+	}
+	if err := cfg.Set(UserConfigPath(), "preview.port", "3000"); err != nil {
+		t.Errorf("Set fixing the user config: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// NoteTypeConfig declares a kind of note that notes create can make.
+type NoteTypeConfig struct {
+	Name          string `yaml:"name"`
+	Description   string `yaml:"description"`
+	Dir           string `yaml:"dir"`
+	Filename      string `yaml:"filename"`
+	TitleRequired bool   `yaml:"title_required"`
+	Template      string `yaml:"template"`
+}
+
+var filenamePlaceholder = regexp.MustCompile(`{{\s*(\w+)\s*}}`)
+
+var filenameFields = map[string]bool{
+	"date":  true,
+	"time":  true,
+	"year":  true,
+	"month": true,
+	"slug":  true,
+}
+
+func defaultNoteTypes() []NoteTypeConfig {
+	return []NoteTypeConfig{
+		{Name: "daily", Description: "Daily notes (auto-dated)", Dir: "daily", Filename: "{{date}}", Template: "daily.md"},
+		{Name: "project", Description: "Project documentation", Dir: "projects", Filename: "{{slug}}", TitleRequired: true, Template: "project.md"},
+		{Name: "meeting", Description: "Meeting notes", Dir: "meetings", Filename: "{{date}}-{{slug}}", TitleRequired: true, Template: "meeting.md"},
+		{Name: "design", Description: "Technical design documents", Dir: "design", Filename: "{{slug}}", TitleRequired: true, Template: "design.md"},
+		{Name: "learning", Description: "Learning notes and tutorials", Dir: "learning", Filename: "{{slug}}", TitleRequired: true, Template: "learning.md"},
+	}
+}
+
+// NoteType looks up a declared note type by name.
+func (c *Config) NoteType(name string) (NoteTypeConfig, bool) {
+	for _, nt := range c.NoteTypes {
+		if nt.Name == name {
+			return nt, true
+		}
+	}
+	return NoteTypeConfig{}, false
+}
+
+// NoteTypeNames returns the declared note type names in config order.
+func (c *Config) NoteTypeNames() []string {
+	names := make([]string, 0, len(c.NoteTypes))
+	for _, nt := range c.NoteTypes {
+		names = append(names, nt.Name)
+	}
+	return names
+}
+
+// NoteDirs returns the note type folders in config order, without duplicates.
+func (c *Config) NoteDirs() []string {
+	var dirs []string
+	for _, nt := range c.NoteTypes {
+		dirs = appendUnique(dirs, nt.Dir)
+	}
+	return dirs
+}
+
+// InitDirs returns every folder notes init should create.
+func (c *Config) InitDirs() []string {
+	dirs := append([]string{}, c.Directories...)
+	for _, dir := range c.NoteDirs() {
+		dirs = appendUnique(dirs, dir)
+	}
+	return appendUnique(dirs, c.Templates.Dir)
+}
+
+// ScanDirs returns the folders scanned for tasks, search and reports: the
+// configured search_dirs plus every note type folder.
+func (c *Config) ScanDirs() []string {
+	dirs := append([]string{}, c.SearchDirs...)
+	for _, dir := range c.NoteDirs() {
+		dirs = appendUnique(dirs, dir)
+	}
+	return dirs
+}
+
+// FileName expands the filename pattern for a note created at now.
+func (nt NoteTypeConfig) FileName(slug string, now time.Time) string {
+	values := map[string]string{
+		"date":  now.Format("2006-01-02"),
+		"time":  now.Format("1504"),
+		"year":  now.Format("2006"),
+		"month": now.Format("01"),
+		"slug":  slug,
+	}
+
+	name := filenamePlaceholder.ReplaceAllStringFunc(nt.Filename, func(match string) string {
+		field := filenamePlaceholder.FindStringSubmatch(match)[1]
+		return values[field]
+	})
+	name = strings.Trim(name, "-_ ")
+
+	if !strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, ".txt") {
+		name += ".md"
+	}
+	return name
+}
+
+func (nt NoteTypeConfig) validate() []error {
+	var errs []error
+	label := fmt.Sprintf("note_types[%s]", nt.Name)
+
+	if strings.TrimSpace(nt.Name) == "" {
+		return []error{fmt.Errorf("note_types: every type needs a name")}
+	}
+	if strings.ContainsAny(nt.Name, " /\\") {
+		errs = append(errs, fmt.Errorf("%s: name must not contain spaces or slashes", label))
+	}
+	if err := validateRelDir(nt.Dir); err != nil {
+		errs = append(errs, fmt.Errorf("%s.dir: %w", label, err))
+	}
+	if strings.TrimSpace(nt.Filename) == "" {
+		errs = append(errs, fmt.Errorf("%s.filename must not be empty", label))
+	}
+	if strings.ContainsAny(nt.Filename, "/\\") || strings.Contains(nt.Filename, "..") {
+		errs = append(errs, fmt.Errorf("%s.filename must be a file name without / or .. (put folders in dir)", label))
+	}
+	usesSlug := false
+	for _, match := range filenamePlaceholder.FindAllStringSubmatch(nt.Filename, -1) {
+		if !filenameFields[match[1]] {
+			errs = append(errs, fmt.Errorf("%s.filename: unknown placeholder {{%s}} (use date, time, year, month or slug)", label, match[1]))
+		}
+		if match[1] == "slug" {
+			usesSlug = true
+		}
+	}
+	if usesSlug && !nt.TitleRequired {
+		errs = append(errs, fmt.Errorf("%s: filename uses {{slug}} so title_required must be true", label))
+	}
+	if strings.ContainsAny(nt.Template, "/\\") {
+		errs = append(errs, fmt.Errorf("%s.template must be a file name inside the templates folder", label))
+	}
+
+	return errs
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
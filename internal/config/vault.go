@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VaultEnv names the environment variable that points at the vault root.
+const VaultEnv = "NOTES_DIR"
+
+// ResolveBaseDir picks the vault root. An explicit --vault flag wins, then
+// the nearest directory at or above the working directory that contains
+// .notes/, then NOTES_DIR. When discover is false, as for notes init, the
+// working directory is used unless --vault is given. found reports whether
+// a vault was located rather than falling back to the working directory.
+func ResolveBaseDir(vaultFlag string, discover bool) (dir string, found bool, err error) {
+	if vaultFlag != "" {
+		return existingDir(vaultFlag)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if !discover {
+		return wd, true, nil
+	}
+
+	if root, ok := FindVaultRoot(wd); ok {
+		return root, true, nil
+	}
+	if env := os.Getenv(VaultEnv); env != "" {
+		return existingDir(env)
+	}
+	return wd, false, nil
+}
+
+// existingDir resolves a vault path given by the user, which must exist
+func existingDir(path string) (string, bool, error) {
+	dir, err := absPath(path)
+	if err != nil {
+		return "", false, err
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", false, fmt.Errorf("vault directory does not exist: %s", dir)
+	}
+	return dir, true, nil
+}
+
+// FindVaultRoot walks up from start looking for a directory that contains
+// the .notes/ marker, the same way git looks for .git/.
+func FindVaultRoot(start string) (string, bool) {
+	dir := start
+	for {
+		info, err := os.Stat(filepath.Join(dir, VaultDir))
+		if err == nil && info.IsDir() {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func absPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand %s: %w", path, err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return filepath.Abs(path)
+}
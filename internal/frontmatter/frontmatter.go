@@ -0,0 +1,183 @@
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter is the YAML block delimited by --- lines at the top of a note.
+type Frontmatter struct {
+	Fields map[string]interface{}
+	Keys   []string // field names in document order
+	Lines  int      // lines occupied, including both delimiters
+}
+
+// Field is a frontmatter value formatted for display.
+type Field struct {
+	Key   string
+	Value string
+}
+
+// Parse splits frontmatter from the rest of a note. Notes without
+// frontmatter return a nil Frontmatter and the content unchanged. When the
+// block is present but not valid YAML, Lines is still set so callers can
+// skip it, and the error describes the problem.
+func Parse(content []byte) (*Frontmatter, []byte, error) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if len(lines) == 0 || !isDelimiter(lines[0], "---") {
+		return nil, content, nil
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if isDelimiter(lines[i], "---") || isDelimiter(lines[i], "...") {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, content, nil
+	}
+
+	block := bytes.Join(lines[1:end], nil)
+	body := bytes.Join(lines[end+1:], nil)
+	fm := &Frontmatter{Fields: map[string]interface{}{}, Lines: end + 1}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(block, &doc); err != nil {
+		return fm, body, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return fm, body, nil
+	}
+
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fm, body, fmt.Errorf("invalid frontmatter: expected key: value pairs")
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		var value interface{}
+		if err := mapping.Content[i+1].Decode(&value); err != nil {
+			return fm, body, fmt.Errorf("invalid frontmatter field %s: %w", key, err)
+		}
+		if _, seen := fm.Fields[key]; !seen {
+			fm.Keys = append(fm.Keys, key)
+		}
+		fm.Fields[key] = value
+	}
+
+	return fm, body, nil
+}
+
+func isDelimiter(line []byte, delimiter string) bool {
+	return strings.TrimRight(string(line), " \t\r\n") == delimiter
+}
+
+// Has reports whether the field is present.
+func (fm *Frontmatter) Has(key string) bool {
+	if fm == nil {
+		return false
+	}
+	_, ok := fm.Fields[key]
+	return ok
+}
+
+// String returns a field as text. Lists are joined with ", ".
+func (fm *Frontmatter) String(key string) string {
+	if fm == nil {
+		return ""
+	}
+	return formatValue(fm.Fields[key])
+}
+
+// Strings returns a field as a list. A plain string is split on commas so
+// both "tags: [a, b]" and "tags: a, b" work.
+func (fm *Frontmatter) Strings(key string) []string {
+	if fm == nil {
+		return nil
+	}
+
+	var items []string
+	switch v := fm.Fields[key].(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, item := range v {
+			if s := strings.TrimSpace(formatValue(item)); s != "" {
+				items = append(items, s)
+			}
+		}
+	default:
+		for _, item := range strings.Split(formatValue(v), ",") {
+			if s := strings.TrimSpace(item); s != "" {
+				items = append(items, s)
+			}
+		}
+	}
+	return items
+}
+
+// Title returns the title field, if any.
+func (fm *Frontmatter) Title() string {
+	return fm.String("title")
+}
+
+// Tags returns the tags field with a leading # on each tag.
+func (fm *Frontmatter) Tags() []string {
+	var tags []string
+	for _, tag := range fm.Strings("tags") {
+		tags = append(tags, "#"+strings.TrimPrefix(tag, "#"))
+	}
+	return tags
+}
+
+// Display returns every field in document order, formatted for display.
+func (fm *Frontmatter) Display() []Field {
+	if fm == nil {
+		return nil
+	}
+
+	keys := fm.Keys
+	if len(keys) != len(fm.Fields) {
+		keys = make([]string, 0, len(fm.Fields))
+		for key := range fm.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, Field{Key: key, Value: fm.String(key)})
+	}
+	return fields
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 {
+			return v.Format("2006-01-02")
+		}
+		return v.Format("2006-01-02 15:04")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatValue(item))
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		data, _ := yaml.Marshal(v)
+		return strings.TrimSpace(string(data))
+	default:
+		return fmt.Sprint(v)
+	}
+}
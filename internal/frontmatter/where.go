@@ -0,0 +1,55 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is a single --where filter such as status=active or
+// type!=daily.
+type Condition struct {
+	Key    string
+	Value  string
+	Negate bool
+}
+
+// ParseCondition parses key=value, key!=value, or a bare key that only
+// requires the field to be present.
+func ParseCondition(expr string) (Condition, error) {
+	if key, value, ok := strings.Cut(expr, "!="); ok {
+		return newCondition(key, value, true, expr)
+	}
+	if key, value, ok := strings.Cut(expr, "="); ok {
+		return newCondition(key, value, false, expr)
+	}
+	return newCondition(expr, "", false, expr)
+}
+
+func newCondition(key, value string, negate bool, expr string) (Condition, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return Condition{}, fmt.Errorf("invalid filter %q, expected key=value", expr)
+	}
+	return Condition{Key: key, Value: strings.TrimSpace(value), Negate: negate}, nil
+}
+
+// Match reports whether the frontmatter satisfies the condition. List
+// fields match when any item equals the value. Comparison ignores case.
+func (c Condition) Match(fm *Frontmatter) bool {
+	if c.Value == "" && !c.Negate {
+		return fm.Has(c.Key)
+	}
+
+	found := false
+	for _, item := range fm.Strings(c.Key) {
+		if strings.EqualFold(strings.TrimPrefix(item, "#"), strings.TrimPrefix(c.Value, "#")) {
+			found = true
+			break
+		}
+	}
+	if !found && strings.EqualFold(fm.String(c.Key), c.Value) {
+		found = true
+	}
+
+	return found != c.Negate
+}
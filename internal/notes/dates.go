@@ -0,0 +1,279 @@
+package notes
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02T15:04"
+)
+
+// dateTokenKeys are the task tokens that hold dates
+var dateTokenKeys = []string{"due", "start", "scheduled"}
+
+// absoluteDatePattern matches dates that are already written out in full
+var absoluteDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2})?$`)
+
+// clockPattern matches a time of day: 15:00, 9am, 9:30pm
+var clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+
+// parseDate reads a date as written in a task token or on the command line,
+// relative to now. It returns the date (at midnight unless a time of day
+// was given) and whether a time of day was given.
+//
+// Accepted forms: 2024-12-31, 2024-12-31T15:00, today, tomorrow,
+// yesterday, weekday names (mon, friday: the next one, today included),
+// offsets (+3d, +2w, +1m, +1y, -1d), eod, eow (Sunday), eom and eoy. Any
+// of these may be followed by a time of day after T or @, e.g.
+// tomorrow@15:00 or fri@9am.
+func parseDate(value string, now time.Time) (time.Time, bool, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	invalid := fmt.Errorf("invalid date %q (use YYYY-MM-DD[THH:MM], today, tomorrow, fri, +3d, +2w, eow, eom; add @15:00 for a time)", value)
+
+	if date, err := time.ParseInLocation(dateTimeLayout, strings.ToUpper(value), now.Location()); err == nil {
+		return date, true, nil
+	}
+
+	dayPart, clockPart := value, ""
+	if index := strings.LastIndexAny(value, "@t"); index > 0 && clockPattern.MatchString(value[index+1:]) {
+		dayPart, clockPart = value[:index], value[index+1:]
+	}
+
+	day, err := parseDay(dayPart, now)
+	if err != nil {
+		return time.Time{}, false, invalid
+	}
+
+	if clockPart == "" {
+		if dayPart == "eod" {
+			return day.Add(17 * time.Hour), true, nil
+		}
+		return day, false, nil
+	}
+
+	hour, minute, ok := parseClock(clockPart)
+	if !ok {
+		return time.Time{}, false, invalid
+	}
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute), true, nil
+}
+
+// parseDay resolves the date part of a date value to local midnight
+func parseDay(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if date, err := time.ParseInLocation(dateLayout, value, now.Location()); err == nil {
+		return date, nil
+	}
+
+	switch value {
+	case "today", "tod", "eod":
+		return today, nil
+	case "tomorrow", "tom", "tmr":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "eow":
+		// Weeks run Monday to Sunday, like the weekly time report
+		return today.AddDate(0, 0, (7-int(today.Weekday()))%7), nil
+	case "eom":
+		return today.AddDate(0, 1, -today.Day()), nil
+	case "eoy":
+		return time.Date(today.Year(), time.December, 31, 0, 0, 0, 0, today.Location()), nil
+	}
+
+	name := value
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	if weekday, ok := weekdayNames[name]; ok && strings.HasPrefix(fullWeekdayName(weekday), value) {
+		return today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7), nil
+	}
+
+	if len(value) >= 3 && (value[0] == '+' || value[0] == '-') {
+		count, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil {
+			if value[0] == '-' {
+				count = -count
+			}
+			switch value[len(value)-1] {
+			case 'd':
+				return today.AddDate(0, 0, count), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*count), nil
+			case 'm':
+				return today.AddDate(0, count, 0), nil
+			case 'y':
+				return today.AddDate(count, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func fullWeekdayName(day time.Weekday) string {
+	return strings.ToLower(day.String())
+}
+
+func parseClock(value string) (int, int, bool) {
+	match := clockPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, 0, false
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+	if minute > 59 {
+		return 0, 0, false
+	}
+	if match[3] == "" {
+		return hour, minute, hour <= 23
+	}
+
+	// 12-hour clock: 12am is midnight and 12pm is noon
+	if hour < 1 || hour > 12 {
+		return 0, 0, false
+	}
+	hour %= 12
+	if match[3] == "pm" {
+		hour += 12
+	}
+	return hour, minute, true
+}
+
+// formatDate writes a date the way it is stored in task tokens
+func formatDate(date time.Time, hasTime bool) string {
+	if hasTime {
+		return date.Format(dateTimeLayout)
+	}
+	return date.Format(dateLayout)
+}
+
+// startOfDay returns midnight of t's day
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysUntil counts calendar days from now's day to date's day. Rounding
+// absorbs the 23 and 25 hour days around DST changes.
+func daysUntil(date, now time.Time) int {
+	return int(math.Round(startOfDay(date).Sub(startOfDay(now)).Hours() / 24))
+}
+
+// IsOverdue reports whether an open task's due date (or time) has passed
+func (t TaskInfo) IsOverdue(now time.Time) bool {
+	if t.DueDate == nil {
+		return false
+	}
+	if t.DueHasTime {
+		return t.DueDate.Before(now)
+	}
+	return daysUntil(*t.DueDate, now) < 0
+}
+
+// IsDueToday reports whether a task is due later today
+func (t TaskInfo) IsDueToday(now time.Time) bool {
+	return t.DueDate != nil && daysUntil(*t.DueDate, now) == 0 && !t.IsOverdue(now)
+}
+
+// NormalizeDates rewrites relative dates in task tokens (due:tomorrow,
+// due:fri@15:00) as absolute dates, so files mean the same thing tomorrow.
+// Examples in frontmatter and code blocks are left as written.
+func (s *Service) NormalizeDates(dryRun bool) error {
+	now := time.Now()
+	changed := 0
+
+	s.walkNotes(func(path string) {
+		lines, err := readLines(path)
+		if err != nil {
+			return
+		}
+
+		relPath, _ := filepath.Rel(s.config.BaseDir, path)
+		noteText := noteTextLines(lines)
+		fileChanged := false
+		for i, line := range lines {
+			if !noteText[i] || !taskLinePattern.MatchString(line) {
+				continue
+			}
+			updated, err := normalizeDateTokens(line, now)
+			if err != nil {
+				fmt.Printf("\033[1;33m⚠ %s:L%d: %v\033[0m\n", relPath, i+1, err)
+				continue
+			}
+			if updated == line {
+				continue
+			}
+			fmt.Printf("\033[1;34m%s:L%d\033[0m\n", relPath, i+1)
+			fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(line))
+			fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(updated))
+			lines[i] = updated
+			fileChanged = true
+			changed++
+		}
+
+		if fileChanged && !dryRun {
+			if err := writeLines(path, lines); err != nil {
+				fmt.Printf("\033[1;31m✗ %s: %v\033[0m\n", relPath, err)
+			}
+		}
+	})
+
+	switch {
+	case changed == 0:
+		fmt.Printf("✅ All task dates are already absolute\n")
+	case dryRun:
+		fmt.Printf("\n\033[90m%d date%s would be rewritten (dry run)\033[0m\n", changed, pluralize(changed))
+	default:
+		fmt.Printf("\n✅ Rewrote %d relative date%s\n", changed, pluralize(changed))
+	}
+	return nil
+}
+
+// normalizeDateTokens rewrites each relative date token on a line
+func normalizeDateTokens(line string, now time.Time) (string, error) {
+	for _, key := range dateTokenKeys {
+		pattern := regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(key) + `:(\S+)`)
+		match := pattern.FindStringSubmatch(line)
+		if match == nil || absoluteDatePattern.MatchString(strings.ToUpper(match[2])) {
+			continue
+		}
+		date, hasTime, err := parseDate(match[2], now)
+		if err != nil {
+			return line, fmt.Errorf("%s: %w", key, err)
+		}
+		line = strings.Replace(line, key+":"+match[2], key+":"+formatDate(date, hasTime), 1)
+	}
+	return line, nil
+}
+
+// HandleDatesCommand processes notes dates subcommands
+func (s *Service) HandleDatesCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("dates command requires a subcommand")
+	}
+
+	switch args[0] {
+	case "normalize":
+		dryRun := false
+		for _, arg := range args[1:] {
+			if arg != "--dry-run" && arg != "-n" {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			dryRun = true
+		}
+		return s.NormalizeDates(dryRun)
+	default:
+		return fmt.Errorf("unknown dates command: %s", args[0])
+	}
+}
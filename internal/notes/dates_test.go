@@ -0,0 +1,109 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testNow is a Wednesday
+var testNow = time.Date(2024, 3, 13, 10, 30, 0, 0, time.UTC)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		hasTime bool
+	}{
+		{value: "2024-12-31", want: "2024-12-31"},
+		{value: "2024-12-31T15:00", want: "2024-12-31T15:00", hasTime: true},
+		{value: "today", want: "2024-03-13"},
+		{value: "tomorrow", want: "2024-03-14"},
+		{value: "yesterday", want: "2024-03-12"},
+		{value: "Tomorrow@15:00", want: "2024-03-14T15:00", hasTime: true},
+		{value: "wed", want: "2024-03-13"},
+		{value: "fri", want: "2024-03-15"},
+		{value: "monday", want: "2024-03-18"},
+		{value: "fri@9am", want: "2024-03-15T09:00", hasTime: true},
+		{value: "fri@12am", want: "2024-03-15T00:00", hasTime: true},
+		{value: "+3d", want: "2024-03-16"},
+		{value: "+2w", want: "2024-03-27"},
+		{value: "+1m", want: "2024-04-13"},
+		{value: "+1y", want: "2025-03-13"},
+		{value: "-1d", want: "2024-03-12"},
+		{value: "eod", want: "2024-03-13T17:00", hasTime: true},
+		{value: "eow", want: "2024-03-17"},
+		{value: "eom", want: "2024-03-31"},
+		{value: "eoy", want: "2024-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			date, hasTime, err := parseDate(tt.value, testNow)
+			if err != nil {
+				t.Fatalf("parseDate(%q): %v", tt.value, err)
+			}
+			if got := formatDate(date, hasTime); got != tt.want || hasTime != tt.hasTime {
+				t.Errorf("parseDate(%q) = %s (time %v), want %s (time %v)", tt.value, got, hasTime, tt.want, tt.hasTime)
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "someday", "2024-13-01", "fri@25:00", "fri@9:75", "+3x", "thursdays"} {
+		if date, _, err := parseDate(value, testNow); err == nil {
+			t.Errorf("parseDate(%q) = %s, want an error", value, date)
+		}
+	}
+}
+
+func TestNormalizeDates(t *testing.T) {
+	note := lines(
+		"- [ ] Call the bank due:tomorrow",
+		"- [ ] Pay rent due:2024-04-01",
+		"- [ ] Fix the bike due:someday",
+		"Meet due:tomorrow, not a task")
+
+	s := newTestVault(t, map[string]string{"todos/home.md": note})
+	if err := s.HandleDatesCommand([]string{"normalize", "--dry-run"}); err != nil {
+		t.Fatalf("dates normalize --dry-run: %v", err)
+	}
+	if got := readNote(t, s, "todos/home.md"); got != note {
+		t.Errorf("dry run changed the note:\n%s", got)
+	}
+
+	if err := s.HandleDatesCommand([]string{"normalize"}); err != nil {
+		t.Fatalf("dates normalize: %v", err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(dateLayout)
+	want := lines(
+		"- [ ] Call the bank due:"+tomorrow,
+		"- [ ] Pay rent due:2024-04-01",
+		"- [ ] Fix the bike due:someday",
+		"Meet due:tomorrow, not a task")
+	if got := readNote(t, s, "todos/home.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNormalizeDatesSkipsCode(t *testing.T) {
+	note := lines(
+		"---",
+		"example: |",
+		"  - [ ] Call the bank due:tomorrow",
+		"---",
+		"~~~",
+		"- [ ] Call the bank due:tomorrow",
+		"~~~",
+		"- [ ] Call the bank due:tomorrow")
+	s := newTestVault(t, map[string]string{"todos/home.md": note})
+	if err := s.HandleDatesCommand([]string{"normalize"}); err != nil {
+		t.Fatalf("dates normalize: %v", err)
+	}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(dateLayout)
+	want := note[:strings.LastIndex(note, "due:tomorrow")] + "due:" + tomorrow + "\n"
+	if got := readNote(t, s, "todos/home.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,396 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// idTokenPattern matches an id: token, an alternative to a ^id anchor
+	idTokenPattern = regexp.MustCompile(`(^|\s)id:(\S+)`)
+	// afterPattern matches after:<id>[,<id>...], the tasks this one waits for
+	afterPattern = regexp.MustCompile(`(^|\s)after:(\S+)`)
+	// blocksPattern matches blocks:<id>[,<id>...], the tasks waiting for this one
+	blocksPattern = regexp.MustCompile(`(^|\s)blocks:(\S+)`)
+)
+
+// parseTaskRefs splits a comma-separated list of task IDs. A leading ^ is
+// allowed, as in ^t-3f9a01c2.
+func parseTaskRefs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimPrefix(strings.TrimSpace(id), "^"); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// taskRef names a task in dependency output: its ID if it has one,
+// otherwise its text
+func taskRef(task TaskInfo) string {
+	if task.ID != "" {
+		return "^" + task.ID
+	}
+	return fmt.Sprintf("%q", task.Text)
+}
+
+// depGraph links each task to the tasks it depends on, by index into tasks
+type depGraph struct {
+	tasks     []TaskInfo
+	byID      map[string]int
+	ambiguous map[string]bool
+	deps      [][]int
+	missing   [][]string
+}
+
+// buildDepGraph resolves after: and blocks: references across tasks. A
+// task with blocks:x counts as a dependency of x. An ID that more than one
+// task has resolves to none of them, and is listed as missing.
+func buildDepGraph(tasks []TaskInfo) *depGraph {
+	g := &depGraph{
+		tasks:     tasks,
+		byID:      make(map[string]int),
+		ambiguous: make(map[string]bool),
+		deps:      make([][]int, len(tasks)),
+		missing:   make([][]string, len(tasks)),
+	}
+	for i, task := range tasks {
+		switch _, taken := g.byID[task.ID]; {
+		case task.ID == "" || g.ambiguous[task.ID]:
+		case taken:
+			delete(g.byID, task.ID)
+			g.ambiguous[task.ID] = true
+		default:
+			g.byID[task.ID] = i
+		}
+	}
+
+	for i, task := range tasks {
+		for _, id := range task.After {
+			if j, ok := g.byID[id]; ok {
+				g.addDep(i, j)
+			} else {
+				g.missing[i] = append(g.missing[i], id)
+			}
+		}
+		for _, id := range task.Blocks {
+			if j, ok := g.byID[id]; ok {
+				g.addDep(j, i)
+			} else {
+				g.missing[i] = append(g.missing[i], id)
+			}
+		}
+	}
+	return g
+}
+
+func (g *depGraph) addDep(task, dep int) {
+	for _, existing := range g.deps[task] {
+		if existing == dep {
+			return
+		}
+	}
+	g.deps[task] = append(g.deps[task], dep)
+}
+
+// dependents returns the tasks that depend directly on task
+func (g *depGraph) dependents(task int) []int {
+	var result []int
+	for i, deps := range g.deps {
+		for _, dep := range deps {
+			if dep == task {
+				result = append(result, i)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// isSettled reports whether a dependency no longer holds anything up.
+// Cancelled tasks will never be done, so they don't block either.
+func isSettled(task TaskInfo) bool {
+	return task.Status == StatusDone || task.Status == StatusCancelled
+}
+
+// findCycle returns a dependency cycle reachable from the given tasks (or
+// from every task when none are given) as a path that starts and ends at
+// the same task, or nil if there is none
+func (g *depGraph) findCycle(from ...int) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.tasks))
+	var path []int
+
+	var visit func(i int) []int
+	visit = func(i int) []int {
+		state[i] = visiting
+		path = append(path, i)
+		for _, dep := range g.deps[i] {
+			switch state[dep] {
+			case visiting:
+				for start, node := range path {
+					if node == dep {
+						return append(append([]int{}, path[start:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+
+	if len(from) == 0 {
+		for i := range g.tasks {
+			from = append(from, i)
+		}
+	}
+	for _, i := range from {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+func (g *depGraph) cycleError(cycle []int) error {
+	refs := make([]string, len(cycle))
+	for i, task := range cycle {
+		refs[i] = taskRef(g.tasks[task])
+	}
+	return fmt.Errorf("dependency cycle: %s", strings.Join(refs, " → "))
+}
+
+// markBlocked fills in BlockedBy for every task whose dependencies aren't
+// all checked off yet. A dependency on an ambiguous ID blocks the task
+// until the IDs are told apart, since either task might be the one meant.
+func (g *depGraph) markBlocked() {
+	for i := range g.tasks {
+		g.tasks[i].BlockedBy = nil
+		for _, dep := range g.deps[i] {
+			if !isSettled(g.tasks[dep]) {
+				g.tasks[i].BlockedBy = append(g.tasks[i].BlockedBy, taskRef(g.tasks[dep]))
+			}
+		}
+		for _, id := range g.missing[i] {
+			if g.ambiguous[id] {
+				g.tasks[i].BlockedBy = append(g.tasks[i].BlockedBy, "^"+id+" (ambiguous)")
+			}
+		}
+	}
+}
+
+// resolveDependencies marks the tasks that are blocked by unfinished
+// after: or blocks: dependencies. It reports an error for a dependency
+// cycle; the tasks in it stay blocked.
+func resolveDependencies(tasks []TaskInfo) error {
+	g := buildDepGraph(tasks)
+	g.markBlocked()
+	if cycle := g.findCycle(); cycle != nil {
+		return g.cycleError(cycle)
+	}
+	return nil
+}
+
+// IsBlocked reports whether an open task is waiting for other tasks
+func (t TaskInfo) IsBlocked() bool {
+	return len(t.BlockedBy) > 0 && t.Status.IsOpen()
+}
+
+// IsReady reports whether an open task can be worked on now: nothing it
+// depends on is unfinished and it isn't waiting on anyone
+func (t TaskInfo) IsReady(now time.Time) bool {
+	return t.Status.IsOpen() && !t.IsBlocked() && !t.IsWaiting() && !t.IsUpcoming(now)
+}
+
+// dependencyInfo describes what a blocked task is waiting for in task
+// listings
+func dependencyInfo(task TaskInfo) string {
+	if !task.IsBlocked() {
+		return ""
+	}
+	return fmt.Sprintf(" \033[31m⛔ after %s\033[0m", strings.Join(task.BlockedBy, ", "))
+}
+
+// criticalPath returns the chain of unfinished tasks ending at task whose
+// estimates add up to the most remaining work, in the order they must be
+// done, and that total. The graph must not have a cycle reachable from task.
+func (g *depGraph) criticalPath(task int) ([]int, time.Duration) {
+	remaining := make(map[int]time.Duration)
+	next := make(map[int]int)
+
+	var walk func(i int) time.Duration
+	walk = func(i int) time.Duration {
+		if total, ok := remaining[i]; ok {
+			return total
+		}
+		var own time.Duration
+		if !isSettled(g.tasks[i]) {
+			own, _ = parseDuration(g.tasks[i].Estimate)
+		}
+
+		longest, via := time.Duration(-1), -1
+		for _, dep := range g.deps[i] {
+			if isSettled(g.tasks[dep]) {
+				continue
+			}
+			if total := walk(dep); total > longest {
+				longest, via = total, dep
+			}
+		}
+		next[i] = via
+		if via >= 0 {
+			own += longest
+		}
+		remaining[i] = own
+		return own
+	}
+
+	total := walk(task)
+	var path []int
+	for i := task; i >= 0; i = next[i] {
+		path = append([]int{i}, path...)
+	}
+	return path, total
+}
+
+// ShowDependencies prints the tasks a task depends on as a tree, the tasks
+// waiting for it, and the critical path of remaining work leading to it
+func (s *Service) ShowDependencies(query string) error {
+	var tasks []TaskInfo
+	s.walkNotes(func(path string) {
+		tasks = append(tasks, s.extractTasks(path)...)
+	})
+	g := buildDepGraph(tasks)
+	g.markBlocked()
+
+	target, ok := g.byID[strings.TrimPrefix(query, "^")]
+	if g.ambiguous[strings.TrimPrefix(query, "^")] {
+		return fmt.Errorf("ambiguous ID %s: more than one task has it; give one of them a new ID", query)
+	}
+	if !ok {
+		found, err := s.findTaskByText(query)
+		if err != nil {
+			return err
+		}
+		for i := range tasks {
+			if tasks[i].FilePath == found.FilePath && tasks[i].Line == found.Line {
+				target = i
+			}
+		}
+	}
+
+	if cycle := g.findCycle(target); cycle != nil {
+		return g.cycleError(cycle)
+	}
+
+	task := tasks[target]
+	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+	fmt.Printf("\033[1;36m🔗 Dependencies: %s\033[0m\n", task.Text)
+	fmt.Printf("\033[90m%s:L%d\033[0m\n", relPath, task.Line)
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+
+	fmt.Println(s.dependencyLine(task))
+	shown := make(map[int]bool)
+	s.printDependencyTree(g, target, "", shown)
+	if len(g.deps[target]) == 0 && len(g.missing[target]) == 0 {
+		fmt.Printf("\033[90m  (no dependencies)\033[0m\n")
+	}
+
+	if dependents := g.dependents(target); len(dependents) > 0 {
+		fmt.Printf("\n\033[1mUnblocks:\033[0m\n")
+		for _, i := range dependents {
+			fmt.Printf("  %s\n", s.dependencyLine(tasks[i]))
+		}
+	}
+
+	path, total := g.criticalPath(target)
+	fmt.Println()
+	if total == 0 && len(path) <= 1 {
+		fmt.Printf("\033[90mCritical path: no estimated work left (add est: to tasks)\033[0m\n")
+		return nil
+	}
+	steps := make([]string, len(path))
+	for i, step := range path {
+		estimate := "no est"
+		if tasks[step].Estimate != "" {
+			estimate = tasks[step].Estimate
+		}
+		steps[i] = fmt.Sprintf("%s (%s)", tasks[step].Text, estimate)
+	}
+	fmt.Printf("\033[1mCritical path (%s remaining):\033[0m\n", formatDuration(total))
+	fmt.Printf("  %s\n", strings.Join(steps, " → "))
+	return nil
+}
+
+// printDependencyTree prints the dependencies of task below it. Tasks that
+// several others depend on are expanded only the first time.
+func (s *Service) printDependencyTree(g *depGraph, task int, prefix string, shown map[int]bool) {
+	shown[task] = true
+	deps := g.deps[task]
+	missing := g.missing[task]
+
+	for n, dep := range deps {
+		last := n == len(deps)-1 && len(missing) == 0
+		branch, indent := "├─ ", "│  "
+		if last {
+			branch, indent = "└─ ", "   "
+		}
+		line := s.dependencyLine(g.tasks[dep])
+		if shown[dep] && len(g.deps[dep]) > 0 {
+			fmt.Printf("%s%s%s \033[90m(see above)\033[0m\n", prefix, branch, line)
+			continue
+		}
+		fmt.Printf("%s%s%s\n", prefix, branch, line)
+		s.printDependencyTree(g, dep, prefix+indent, shown)
+	}
+	for n, id := range missing {
+		branch := "├─ "
+		if n == len(missing)-1 {
+			branch = "└─ "
+		}
+		problem := "no task has this ID"
+		if g.ambiguous[id] {
+			problem = "more than one task has this ID"
+		}
+		fmt.Printf("%s%s\033[1;33m⚠ ^%s: %s\033[0m\n", prefix, branch, id, problem)
+	}
+}
+
+// dependencyLine shows a task's status, text, ID and estimate
+func (s *Service) dependencyLine(task TaskInfo) string {
+	mark := "⚪"
+	switch {
+	case task.Status == StatusDone:
+		mark = "✅"
+	case task.Status == StatusCancelled:
+		mark = "\033[90m[-]\033[0m"
+	case task.IsBlocked():
+		mark = "⛔"
+	case task.Status == StatusInProgress:
+		mark = "🔵"
+	}
+
+	line := fmt.Sprintf("%s %s", mark, task.Text)
+	if task.ID != "" {
+		line += fmt.Sprintf(" \033[90m^%s\033[0m", task.ID)
+	}
+	if task.Estimate != "" {
+		line += fmt.Sprintf(" \033[90m~%s\033[0m", task.Estimate)
+	}
+	return line
+}
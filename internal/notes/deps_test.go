@@ -0,0 +1,113 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	task := func(id string, status TaskStatus, after, blocks string) TaskInfo {
+		return TaskInfo{
+			Text:   "Task " + id,
+			ID:     id,
+			Status: status,
+			After:  parseTaskRefs(after),
+			Blocks: parseTaskRefs(blocks),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tasks   []TaskInfo
+		blocked map[string]string // task ID to what blocks it
+		cycle   string            // expected cycle error, if any
+	}{
+		{
+			name: "after and blocks",
+			tasks: []TaskInfo{
+				task("a", StatusTodo, "", ""),
+				task("b", StatusTodo, "^a", ""),
+				task("c", StatusTodo, "", "b,d"),
+				task("d", StatusTodo, "", ""),
+			},
+			blocked: map[string]string{"b": "^a, ^c", "d": "^c"},
+		},
+		{
+			name: "settled dependencies",
+			tasks: []TaskInfo{
+				task("a", StatusDone, "", ""),
+				task("b", StatusCancelled, "", ""),
+				task("c", StatusTodo, "a,b", ""),
+			},
+		},
+		{
+			name: "missing dependency",
+			tasks: []TaskInfo{
+				task("a", StatusTodo, "nope", ""),
+			},
+		},
+		{
+			name: "cycle",
+			tasks: []TaskInfo{
+				task("a", StatusTodo, "c", ""),
+				task("b", StatusTodo, "a", ""),
+				task("c", StatusTodo, "b", ""),
+				task("d", StatusTodo, "", ""),
+			},
+			blocked: map[string]string{"a": "^c", "b": "^a", "c": "^b"},
+			cycle:   "dependency cycle: ^a → ^c → ^b → ^a",
+		},
+		{
+			name: "cycle through blocks",
+			tasks: []TaskInfo{
+				task("a", StatusTodo, "", "b"),
+				task("b", StatusTodo, "", "a"),
+			},
+			blocked: map[string]string{"a": "^b", "b": "^a"},
+			cycle:   "dependency cycle: ^a → ^b → ^a",
+		},
+		{
+			name: "self dependency",
+			tasks: []TaskInfo{
+				task("a", StatusTodo, "a", ""),
+			},
+			blocked: map[string]string{"a": "^a"},
+			cycle:   "dependency cycle: ^a → ^a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveDependencies(tt.tasks)
+			switch {
+			case tt.cycle == "" && err != nil:
+				t.Errorf("resolveDependencies: %v", err)
+			case tt.cycle != "" && (err == nil || err.Error() != tt.cycle):
+				t.Errorf("resolveDependencies = %v, want %s", err, tt.cycle)
+			}
+
+			for _, task := range tt.tasks {
+				if got := strings.Join(task.BlockedBy, ", "); got != tt.blocked[task.ID] {
+					t.Errorf("%s blocked by %q, want %q", task.ID, got, tt.blocked[task.ID])
+				}
+			}
+		})
+	}
+}
+
+func TestShowDependenciesCycle(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/launch.md": lines(
+			"- [ ] Write copy after:t-review ^t-copy",
+			"- [ ] Review copy after:t-copy ^t-review",
+			"- [ ] Order swag ^t-swag"),
+	})
+
+	err := s.ShowDependencies("^t-copy")
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("ShowDependencies(^t-copy) = %v, want a dependency cycle error", err)
+	}
+	if err := s.ShowDependencies("^t-swag"); err != nil {
+		t.Errorf("ShowDependencies(^t-swag) = %v, want no error outside the cycle", err)
+	}
+}
@@ -0,0 +1,291 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"notes/internal/frontmatter"
+)
+
+// NoteMatch is a note found by the fuzzy finder
+type NoteMatch struct {
+	Path     string
+	RelPath  string
+	Title    string
+	Score    int
+	Modified time.Time
+}
+
+// maxPickerItems caps the interactive selection list
+const maxPickerItems = 10
+
+// Find prints notes matching query, best match first
+func (s *Service) Find(query string, opts FindOptions) error {
+	matches := s.findNotes(query)
+
+	if opts.Print {
+		for _, match := range matches {
+			fmt.Println(match.Path)
+		}
+		return nil
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("\033[90mNo notes match \"%s\".\033[0m\n", query)
+		return nil
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	for i, match := range matches {
+		if i >= limit {
+			fmt.Printf("\033[90m... and %d more\033[0m\n", len(matches)-limit)
+			break
+		}
+		fmt.Printf("%s\n", formatNoteMatch(match))
+	}
+
+	return nil
+}
+
+// Open opens the best note matching query, asking which one on a terminal
+// when several match
+func (s *Service) Open(query string, opts FindOptions) error {
+	matches := s.findNotes(query)
+	if len(matches) == 0 {
+		return fmt.Errorf("no notes match: %s", query)
+	}
+
+	choice := matches[0]
+	if !opts.Print && len(matches) > 1 && isInteractive() {
+		picked, err := pickNote(matches)
+		if err != nil {
+			return err
+		}
+		choice = picked
+	}
+
+	if opts.Print {
+		fmt.Println(choice.Path)
+		return nil
+	}
+
+	return s.openEditor(choice.Path, 0)
+}
+
+// pickNote shows a numbered list of matches and reads the user's choice
+func pickNote(matches []NoteMatch) (NoteMatch, error) {
+	count := len(matches)
+	if count > maxPickerItems {
+		count = maxPickerItems
+	}
+
+	for i := 0; i < count; i++ {
+		fmt.Printf("\033[1m[%d]\033[0m %s\n", i+1, formatNoteMatch(matches[i]))
+	}
+
+	for {
+		answer, err := promptLine(fmt.Sprintf("Open which note? [1-%d] (1): ", count))
+		if err != nil {
+			return NoteMatch{}, fmt.Errorf("no note selected")
+		}
+		if answer == "" {
+			return matches[0], nil
+		}
+		if index, err := strconv.Atoi(answer); err == nil && index >= 1 && index <= count {
+			return matches[index-1], nil
+		}
+		fmt.Printf("Please enter a number between 1 and %d\n", count)
+	}
+}
+
+func formatNoteMatch(match NoteMatch) string {
+	title := ""
+	if match.Title != "" {
+		title = fmt.Sprintf(" \033[90m%s\033[0m", match.Title)
+	}
+	return fmt.Sprintf("\033[1;34m%s\033[0m%s \033[90m(%s)\033[0m",
+		match.RelPath, title, formatAge(match.Modified))
+}
+
+// findNotes fuzzy-matches query against every note's path and title and
+// ranks the results by match quality and then by how recently each note
+// changed
+func (s *Service) findNotes(query string) []NoteMatch {
+	recent := s.gitRecency()
+	now := time.Now()
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matches []NoteMatch
+	s.walkNotes(func(path string) {
+		relPath, _ := filepath.Rel(s.config.BaseDir, path)
+		relPath = filepath.ToSlash(relPath)
+		title := noteTitle(path)
+
+		score, ok := fuzzyScore(query, strings.ToLower(relPath+" "+title))
+		if !ok {
+			return
+		}
+
+		modified := recent[relPath]
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+
+		// Notes touched in the last month get a small boost
+		if days := int(now.Sub(modified).Hours() / 24); days < 30 {
+			score += (30 - days) / 3
+		}
+
+		matches = append(matches, NoteMatch{
+			Path:     path,
+			RelPath:  relPath,
+			Title:    title,
+			Score:    score,
+			Modified: modified,
+		})
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Modified.After(matches[j].Modified)
+	})
+
+	return matches
+}
+
+// fuzzyScore matches the query's characters in order within text. Whole
+// substring matches, matches at word starts and consecutive runs score
+// higher. An empty query matches everything.
+func fuzzyScore(query, text string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	score := 0
+	if index := strings.Index(text, query); index >= 0 {
+		score += 50
+		if index == 0 || !isWordChar(rune(text[index-1])) {
+			score += 25
+		}
+	}
+
+	queryRunes := []rune(query)
+	textRunes := []rune(text)
+	qi := 0
+	run := 0
+	for ti := 0; ti < len(textRunes) && qi < len(queryRunes); ti++ {
+		if unicode.IsSpace(queryRunes[qi]) {
+			qi++
+			run = 0
+			continue
+		}
+		if textRunes[ti] != queryRunes[qi] {
+			run = 0
+			continue
+		}
+
+		score++
+		if ti == 0 || !isWordChar(textRunes[ti-1]) {
+			score += 3
+		}
+		run++
+		score += run
+		qi++
+	}
+
+	if qi < len(queryRunes) {
+		return 0, false
+	}
+	return score, true
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// noteTitle returns the frontmatter title or the first "# " heading
+func noteTitle(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	fm, body, _ := frontmatter.Parse(content)
+	if title := fm.Title(); title != "" {
+		return title
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for lines := 0; scanner.Scan() && lines < 50; lines++ {
+		trimmed := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(trimmed, "# ") {
+			return strings.TrimPrefix(trimmed, "# ")
+		}
+	}
+
+	return ""
+}
+
+// gitRecency maps vault-relative paths to their latest commit time
+func (s *Service) gitRecency() map[string]time.Time {
+	recent := make(map[string]time.Time)
+
+	cmd := exec.Command("git", "log", "--format=%ct", "--name-only", "--relative")
+	cmd.Dir = s.config.BaseDir
+	output, err := cmd.Output()
+	if err != nil {
+		return recent
+	}
+
+	var commitTime time.Time
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if seconds, err := strconv.ParseInt(line, 10, 64); err == nil {
+			commitTime = time.Unix(seconds, 0)
+			continue
+		}
+		// git log lists newest commits first, so keep the first time seen
+		if _, seen := recent[line]; !seen {
+			recent[line] = commitTime
+		}
+	}
+
+	return recent
+}
+
+// formatAge describes how long ago a time was, for listings
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+
+	age := time.Since(t)
+	switch {
+	case age < time.Hour:
+		return "just now"
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	default:
+		return t.Format("Jan 2, 2006")
+	}
+}
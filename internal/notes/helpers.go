@@ -1,39 +1,45 @@
 package notes
 
 import (
+	"bufio"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"notes/internal/templates"
 )
 
 func (s *Service) createTemplateFiles() error {
-	templateDir := filepath.Join(s.config.BaseDir, "templates")
+	templateDir := filepath.Join(s.config.BaseDir, s.config.Templates.Dir)
 	
-	templateFiles := map[string]string{
-		"daily.md":    "Daily note template",
-		"project.md":  "Project documentation template", 
-		"meeting.md":  "Meeting notes template",
-		"design.md":   "Design document template",
-		"learning.md": "Learning notes template",
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		return fmt.Errorf("failed to create templates directory: %w", err)
 	}
 	
-	for filename, description := range templateFiles {
+	for _, nt := range s.config.NoteTypes {
+		if nt.Template == "" {
+			continue
+		}
+		filename := nt.Template
 		templateFile := filepath.Join(templateDir, filename)
 		if _, err := os.Stat(templateFile); os.IsNotExist(err) {
-			content := fmt.Sprintf("# %s\n\nThis is a template for %s.\n", filename, description)
+			content := templates.Source(NoteType(nt.Name))
 			if err := os.WriteFile(templateFile, []byte(content), 0644); err != nil {
 				return fmt.Errorf("failed to create template %s: %w", filename, err)
 			}
-			fmt.Printf("✓ Created template: templates/%s\n", filename)
+			fmt.Printf("✓ Created template: %s/%s\n", s.config.Templates.Dir, filename)
 		} else {
-			fmt.Printf("⚠ Template already exists: templates/%s\n", filename)
+			fmt.Printf("⚠ Template already exists: %s/%s\n", s.config.Templates.Dir, filename)
 		}
 	}
 	
@@ -92,43 +98,166 @@ Each note type uses a structured template to maintain consistency:
 	return nil
 }
 
-// formatRelativeTime converts a date to relative time display
-func formatRelativeTime(date *time.Time) string {
-	if date == nil {
-		return ""
+var stdin = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether stdin is a terminal we can prompt on
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// promptLine prints a prompt and reads one line of input
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// resolveVariables fills template variables from --var values first, then
+// by prompting on a terminal, then from each variable's default. Values
+// for undeclared names are passed through so templates can still use them.
+func resolveVariables(declared []templates.Variable, provided map[string]string) (map[string]string, error) {
+	values := make(map[string]string)
+	for name, value := range provided {
+		values[name] = value
 	}
 	
-	now := time.Now()
-	today := now.Format("2006-01-02")
-	dateStr := date.Format("2006-01-02")
+	interactive := isInteractive()
 	
-	if dateStr == today {
-		return "today"
+	for _, v := range declared {
+		if value, ok := values[v.Name]; ok {
+			if !v.Allows(value) {
+				return nil, fmt.Errorf("invalid value %q for %s. Choose one of: %s", value, v.Name, strings.Join(v.Choices, ", "))
+			}
+			continue
+		}
+		
+		if !interactive {
+			values[v.Name] = v.Default
+			continue
+		}
+		
+		prompt := v.Prompt
+		if len(v.Choices) > 0 {
+			prompt += " [" + strings.Join(v.Choices, "/") + "]"
+		}
+		if v.Default != "" {
+			prompt += fmt.Sprintf(" (%s)", v.Default)
+		}
+		prompt += ": "
+		
+		for {
+			answer, err := promptLine(prompt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", v.Name, err)
+			}
+			if answer == "" {
+				answer = v.Default
+			}
+			if v.Allows(answer) {
+				values[v.Name] = answer
+				break
+			}
+			fmt.Printf("Please choose one of: %s\n", strings.Join(v.Choices, ", "))
+		}
 	}
 	
-	duration := now.Sub(*date)
-	days := int(duration.Hours() / 24)
+	return values, nil
+}
+
+// userName returns the name templates use for {{ user }}: the user.name
+// setting, then git's user.name, then the login name.
+func (s *Service) userName() string {
+	if s.config.User.Name != "" {
+		return s.config.User.Name
+	}
 	
-	if days == 1 {
-		if dateStr < today {
-			return "1 day overdue"
+	cmd := exec.Command("git", "config", "user.name")
+	cmd.Dir = s.config.BaseDir
+	if output, err := cmd.Output(); err == nil {
+		if name := strings.TrimSpace(string(output)); name != "" {
+			return name
 		}
-		return "tomorrow"
 	}
 	
-	if days > 0 && dateStr < today {
-		return fmt.Sprintf("%d days overdue", days)
+	return os.Getenv("USER")
+}
+
+// previousDailyNote returns the vault-relative path of the latest daily
+// note dated before the given day, or "" if there is none.
+func (s *Service) previousDailyNote(before time.Time) string {
+	nt, ok := s.config.NoteType(string(Daily))
+	if !ok {
+		return ""
+	}
+	
+	entries, err := os.ReadDir(filepath.Join(s.config.BaseDir, nt.Dir))
+	if err != nil {
+		return ""
 	}
 	
-	if days < 0 {
-		futureDays := -days
-		if futureDays == 1 {
-			return "tomorrow"
+	cutoff := before.Format("2006-01-02")
+	latest := ""
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		if entry.IsDir() || len(name) != len(cutoff) || name >= cutoff {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", name); err == nil && name > latest {
+			latest = name
 		}
-		return fmt.Sprintf("in %d days", futureDays)
 	}
 	
-	return date.Format("Jan 2")
+	if latest == "" {
+		return ""
+	}
+	return filepath.ToSlash(filepath.Join(nt.Dir, latest+".md"))
+}
+
+// formatRelativeTime converts a due date to relative time display. Times
+// of day are shown for due times, and due times today count down in hours.
+func formatRelativeTime(date *time.Time, hasTime bool) string {
+	if date == nil {
+		return ""
+	}
+	
+	now := time.Now()
+	days := daysUntil(*date, now)
+	clock := ""
+	if hasTime {
+		clock = " " + date.Format("15:04")
+	}
+	
+	if hasTime && days == 0 {
+		remaining := date.Sub(now)
+		if remaining < 0 {
+			return fmt.Sprintf("%s overdue", formatDuration(-remaining.Truncate(time.Minute)))
+		}
+		if remaining < 6*time.Hour {
+			return fmt.Sprintf("in %s", formatDuration(remaining.Truncate(time.Minute)))
+		}
+	}
+	
+	switch {
+	case days == 0:
+		return "today" + clock
+	case days == 1:
+		return "tomorrow" + clock
+	case days == -1:
+		return "1 day overdue"
+	case days < 0:
+		return fmt.Sprintf("%d days overdue", -days)
+	case days < 7:
+		return fmt.Sprintf("in %d days", days) + clock
+	}
+	
+	return date.Format("Jan 2") + clock
 }
 
 // detectCurrentContext checks if we're in a specific project/context
@@ -138,8 +267,17 @@ func (s *Service) detectCurrentContext() string {
 		return ""
 	}
 	
+	// Resolve symlinks so a vault reached through a link still matches
+	baseDir := s.config.BaseDir
+	if resolved, err := filepath.EvalSymlinks(baseDir); err == nil {
+		baseDir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+	
 	// Check if we're inside the notes directory structure
-	relPath, err := filepath.Rel(s.config.BaseDir, cwd)
+	relPath, err := filepath.Rel(baseDir, cwd)
 	if err != nil || strings.HasPrefix(relPath, "..") {
 		return ""
 	}
@@ -153,6 +291,53 @@ func (s *Service) detectCurrentContext() string {
 	return ""
 }
 
+// walkNotes calls fn for every markdown or text note in the search directories
+func (s *Service) walkNotes(fn func(path string)) {
+	for _, dir := range s.config.ScanDirs() {
+		dirPath := filepath.Join(s.config.BaseDir, dir)
+		
+		filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			
+			if d.IsDir() || !isNoteFile(path) {
+				return nil
+			}
+			
+			fn(path)
+			return nil
+		})
+	}
+}
+
+func isNoteFile(path string) bool {
+	return strings.HasSuffix(path, ".md") || strings.HasSuffix(path, ".txt")
+}
+
+// inScanDirs reports whether a note is in one of the folders walkNotes
+// scans, so the commands that read tasks will see what is written to it
+func (s *Service) inScanDirs(path string) bool {
+	for _, dir := range s.config.ScanDirs() {
+		rel, err := filepath.Rel(filepath.Join(s.config.BaseDir, dir), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateText shortens plain text to at most max characters, ending it
+// with "..." when it is cut. It counts runes, so it never splits a
+// multi-byte character; add colour afterwards.
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
+
 // getTreeChars returns appropriate tree drawing characters
 func getTreeChars(isLast bool) string {
 	if isLast {
@@ -187,20 +372,17 @@ func analyzeTaskStats(tasks []TaskInfo, service *Service) TaskStats {
 	}
 	
 	now := time.Now()
-	todayStr := now.Format("2006-01-02")
 	
 	for _, task := range tasks {
-		priority := service.detectPriority(task.Text)
 		taskLower := strings.ToLower(task.Text)
 		
 		// Categorize by urgency
-		if priority == "🔴" {
+		if task.Priority == PriorityHigh {
 			stats.Urgent = append(stats.Urgent, task)
 		} else if task.DueDate != nil {
-			taskDateStr := task.DueDate.Format("2006-01-02")
-			if taskDateStr < todayStr {
+			if task.IsOverdue(now) {
 				stats.Overdue = append(stats.Overdue, task)
-			} else if taskDateStr == todayStr {
+			} else if task.IsDueToday(now) {
 				stats.Today = append(stats.Today, task)
 			} else {
 				stats.Other = append(stats.Other, task)
@@ -219,7 +401,11 @@ func analyzeTaskStats(tasks []TaskInfo, service *Service) TaskStats {
 			stats.EnergyNeeded = append(stats.EnergyNeeded, task)
 		}
 		
-		if strings.Contains(taskLower, "blocked") || strings.Contains(taskLower, "waiting") ||
+		// Explicit waiting:@person and after: tokens win; otherwise guess
+		// from wording
+		if task.IsWaiting() || task.IsBlocked() {
+			stats.Blocked = append(stats.Blocked, task)
+		} else if strings.Contains(taskLower, "blocked") || strings.Contains(taskLower, "waiting") ||
 		   strings.Contains(taskLower, "pending") {
 			stats.Blocked = append(stats.Blocked, task)
 		}
@@ -263,18 +449,24 @@ func estimateTaskEffort(taskText string) string {
 
 // parseTimeEntry parses a time log entry line
 // Format: "• 2024-01-15 09:30-10:45 (1h15m) - Initial component setup"
+// or, past midnight: "• 2024-01-15 23:30 → 2024-01-16 00:45 (1h15m) - ..."
+// The indent and bullet are kept so formatTimeEntry writes the line back
+// the way it was.
 func parseTimeEntry(line string) (*TimeEntry, error) {
+	indent := line[:indentOf(line)]
+	
 	// Remove leading bullet and whitespace - handle various bullet types
 	line = strings.TrimSpace(line)
-	if strings.HasPrefix(line, "•") {
-		line = strings.TrimSpace(line[len("•"):])
-	} else if strings.HasPrefix(line, "*") {
-		line = strings.TrimSpace(line[1:])
-	} else if strings.HasPrefix(line, "-") {
-		line = strings.TrimSpace(line[1:])
+	bullet := ""
+	for _, b := range []string{"•", "*", "-"} {
+		if strings.HasPrefix(line, b) {
+			bullet = b
+			line = strings.TrimSpace(line[len(b):])
+			break
+		}
 	}
 	
-	// Parse the pattern: "YYYY-MM-DD HH:MM-HH:MM (duration) - description"
+	// Parse the pattern: "<when> (duration) - description"
 	parts := strings.SplitN(line, " - ", 2)
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("invalid time entry format")
@@ -300,39 +492,32 @@ func parseTimeEntry(line string) (*TimeEntry, error) {
 	timePart = strings.TrimSpace(timePart)
 	
 	// Parse date and time range
-	parts = strings.Split(timePart, " ")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid date-time format: expected 2 parts, got %d: %v", len(parts), parts)
-	}
-	
-	dateStr := parts[0]
-	timeRange := parts[1]
-	
-	date, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
-	if err != nil {
-		return nil, fmt.Errorf("invalid date: %w", err)
-	}
-	
-	timeRangeParts := strings.Split(timeRange, "-")
-	if len(timeRangeParts) != 2 {
-		return nil, fmt.Errorf("invalid time range")
+	match := timeRangePattern.FindStringSubmatch(timePart)
+	if match == nil {
+		return nil, fmt.Errorf("invalid date-time format: %q", timePart)
 	}
 	
-	startTime, err := time.Parse("15:04", timeRangeParts[0])
+	startDateTime, err := parseTimeStamp(match[1], match[2], match[3])
 	if err != nil {
 		return nil, fmt.Errorf("invalid start time: %w", err)
 	}
-	
-	endTime, err := time.Parse("15:04", timeRangeParts[1])
+	endDay := strings.TrimSpace(match[4])
+	if endDay == "" {
+		endDay = match[1]
+	}
+	endDateTime, err := parseTimeStamp(endDay, match[5], match[6])
 	if err != nil {
 		return nil, fmt.Errorf("invalid end time: %w", err)
 	}
 	
-	// Combine date with times
-	startDateTime := time.Date(date.Year(), date.Month(), date.Day(), 
-		startTime.Hour(), startTime.Minute(), 0, 0, time.Local)
-	endDateTime := time.Date(date.Year(), date.Month(), date.Day(), 
-		endTime.Hour(), endTime.Minute(), 0, 0, time.Local)
+	// A plain 23:30-00:45 range ends the next day
+	if match[4] == "" && endDateTime.Before(startDateTime) {
+		endDateTime = endDateTime.AddDate(0, 0, 1)
+	}
+	if endDateTime.Before(startDateTime) {
+		return nil, fmt.Errorf("entry ends before it starts")
+	}
+	date := startOfDay(startDateTime)
 	
 	return &TimeEntry{
 		Date:        date,
@@ -340,6 +525,10 @@ func parseTimeEntry(line string) (*TimeEntry, error) {
 		EndTime:     endDateTime,
 		Duration:    duration,
 		Description: description,
+		Indent:      indent,
+		Bullet:      bullet,
+		Seconds:     strings.Count(match[2], ":") == 2,
+		Zone:        match[3] != "",
 	}, nil
 }
 
@@ -408,20 +597,91 @@ func formatDuration(d time.Duration) string {
 	}
 }
 
-// formatTimeEntry formats a time entry for markdown output
+// formatTimeEntry formats a time entry for markdown output, with the
+// indent and bullet it was read with, if any
 func formatTimeEntry(entry TimeEntry) string {
-	dateStr := entry.Date.Format("2006-01-02")
-	startStr := entry.StartTime.Format("15:04")
-	endStr := entry.EndTime.Format("15:04")
-	durationStr := formatDuration(entry.Duration)
+	durationStr := formatEntryDuration(entry)
 	
-	return fmt.Sprintf("  • %s %s-%s (%s) - %s", 
-		dateStr, startStr, endStr, durationStr, entry.Description)
+	bullet := entry.Bullet
+	if bullet == "" {
+		bullet = "•"
+	}
+	return fmt.Sprintf("%s%s %s (%s) - %s", 
+		entry.Indent, bullet, formatEntryRange(entry), durationStr, entry.Description)
+}
+
+// formatEntryDuration writes an entry's duration to the second when the
+// entry has seconds or the duration isn't whole minutes, so reading the
+// line back gives the same duration
+func formatEntryDuration(entry TimeEntry) string {
+	d := entry.Duration
+	if !entry.Seconds && d%time.Minute == 0 {
+		return formatDuration(d)
+	}
+	
+	d = d.Round(time.Second)
+	var b strings.Builder
+	if hours := int(d.Hours()); hours > 0 {
+		fmt.Fprintf(&b, "%dh", hours)
+	}
+	if minutes := int(d.Minutes()) % 60; minutes > 0 {
+		fmt.Fprintf(&b, "%dm", minutes)
+	}
+	if seconds := int(d.Seconds()) % 60; seconds > 0 || b.Len() == 0 {
+		fmt.Fprintf(&b, "%ds", seconds)
+	}
+	return b.String()
+}
+
+// formatEntryRange writes when an entry happened: 2024-01-15 09:30-10:45,
+// or 2024-01-15 23:30 → 2024-01-16 00:45 when it runs past midnight.
+// Seconds and UTC offsets are included if the entry has them.
+func formatEntryRange(entry TimeEntry) string {
+	layout := "15:04"
+	if entry.Seconds {
+		layout = "15:04:05"
+	}
+	if entry.Zone {
+		layout += "Z07:00"
+	}
+	
+	start := entry.StartTime.Format("2006-01-02 ") + entry.StartTime.Format(layout)
+	// A plain range can end at the next midnight, except for a whole day:
+	// 00:00-00:00 would read back as no time at all
+	midnight := startOfDay(entry.StartTime).AddDate(0, 0, 1)
+	wholeDay := entry.EndTime.Equal(midnight) && entry.StartTime.Equal(startOfDay(entry.StartTime))
+	if entry.EndTime.After(midnight) || wholeDay {
+		return start + " → " + entry.EndTime.Format("2006-01-02 ") + entry.EndTime.Format(layout)
+	}
+	return start + "-" + entry.EndTime.Format(layout)
+}
+
+// timeRangePattern matches the date and times of a time log entry: a day
+// and a clock range, or start and end timestamps joined by → for sessions
+// that cross midnight. Times may have seconds and a UTC offset.
+var timeRangePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}) (\d{1,2}:\d{2}(?::\d{2})?)(Z|[+-]\d{2}:\d{2})?(?:-|\s*→\s*(\d{4}-\d{2}-\d{2}\s+)?)(\d{1,2}:\d{2}(?::\d{2})?)(Z|[+-]\d{2}:\d{2})?$`)
+
+// parseTimeStamp reads a log entry's date and time of day, in the local
+// time zone unless zone gives a UTC offset
+func parseTimeStamp(day, clock, zone string) (time.Time, error) {
+	layout := "2006-01-02 15:04"
+	if strings.Count(clock, ":") == 2 {
+		layout += ":05"
+	}
+	if clock[1] == ':' {
+		// 9:30 is 09:30
+		clock = "0" + clock
+	}
+	if zone == "" {
+		return time.ParseInLocation(layout, day+" "+clock, time.Local)
+	}
+	return time.Parse(layout+"Z07:00", day+" "+clock+zone)
 }
 
 // TimerState represents the current timer state
 type TimerState struct {
 	IsActive    bool          `json:"is_active"`
+	TaskID      string        `json:"task_id,omitempty"`
 	TaskText    string        `json:"task_text"`
 	FilePath    string        `json:"file_path"`
 	TaskLine    int           `json:"task_line"`
@@ -429,6 +689,7 @@ type TimerState struct {
 	IsPaused    bool          `json:"is_paused"`
 	PausedTime  time.Time     `json:"paused_time"`
 	TotalPaused time.Duration `json:"total_paused"`
+	Description string        `json:"description,omitempty"`
 }
 
 // Timer state file management
@@ -458,38 +719,54 @@ func (s *Service) clearTimerState() error {
 	return os.Remove(s.getTimerStatePath())
 }
 
-// findTaskByText searches for a task by partial text match
+// findTaskByText searches the open tasks for one by ID or partial text
+// match. Only open tasks can be started or changed; done and cancelled ones
+// are history.
 func (s *Service) findTaskByText(searchText string) (*TaskInfo, error) {
-	searchDirs := []string{"daily", "projects", "meetings", "design", "learning", "todos"}
+	return s.findTask(searchText, false)
+}
+
+// findAnyTask is findTaskByText for commands that work on the history of a
+// task too, such as its time entries, so it also finds done and cancelled
+// tasks
+func (s *Service) findAnyTask(searchText string) (*TaskInfo, error) {
+	return s.findTask(searchText, true)
+}
+
+func (s *Service) findTask(searchText string, includeFinished bool) (*TaskInfo, error) {
 	searchLower := strings.ToLower(searchText)
 	
+	searchID := strings.TrimPrefix(searchText, "^")
+	
 	var matches []TaskInfo
+	var idMatches []TaskInfo
 	
-	for _, dir := range searchDirs {
-		dirPath := filepath.Join(s.config.BaseDir, dir)
-		
-		err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
+	s.walkNotes(func(path string) {
+		for _, task := range s.extractTasks(path) {
+			if !task.Status.IsOpen() && !includeFinished {
+				continue
 			}
-			
-			if d.IsDir() || (!strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, ".txt")) {
-				return nil
+			if task.ID != "" && task.ID == searchID {
+				idMatches = append(idMatches, task)
 			}
-			
-			tasks := s.extractTasks(path)
-			for _, task := range tasks {
-				if strings.Contains(strings.ToLower(task.Text), searchLower) {
-					matches = append(matches, task)
-				}
+			if strings.Contains(strings.ToLower(task.Text), searchLower) {
+				matches = append(matches, task)
 			}
-			
-			return nil
-		})
-		
-		if err != nil {
-			continue
 		}
+	})
+	
+	// A stable ID always wins over text matches, but only if it is unique
+	switch len(idMatches) {
+	case 0:
+	case 1:
+		return &idMatches[0], nil
+	default:
+		refs := make([]string, len(idMatches))
+		for i, task := range idMatches {
+			relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+			refs[i] = fmt.Sprintf("%s:L%d", relPath, task.Line)
+		}
+		return nil, fmt.Errorf("ambiguous ID ^%s: %d tasks have it (%s); give one of them a new ID", searchID, len(idMatches), strings.Join(refs, ", "))
 	}
 	
 	if len(matches) == 0 {
@@ -500,15 +777,24 @@ func (s *Service) findTaskByText(searchText string) (*TaskInfo, error) {
 		return &matches[0], nil
 	}
 	
-	// Multiple matches - return the best one (exact match preferred)
+	// Several matches: a task whose whole text was given wins, but only if
+	// it is the only one. Otherwise refuse to guess.
+	var exact []TaskInfo
 	for _, match := range matches {
 		if strings.EqualFold(match.Text, searchText) {
-			return &match, nil
+			exact = append(exact, match)
 		}
 	}
+	if len(exact) == 1 {
+		return &exact[0], nil
+	}
 	
-	// Return first match if no exact match
-	return &matches[0], nil
+	candidates := make([]string, len(matches))
+	for i, task := range matches {
+		relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+		candidates[i] = fmt.Sprintf("%s:L%d %s", relPath, task.Line, task.Text)
+	}
+	return nil, fmt.Errorf("%q matches %d tasks; give more of the text or a ^id:\n  %s", searchText, len(matches), strings.Join(candidates, "\n  "))
 }
 
 // TimeReportData holds aggregated time data for reporting
@@ -516,6 +802,7 @@ type TimeReportData struct {
 	Tasks []TaskTimeData
 	TotalTime time.Duration
 	Period string
+	Person string
 	StartDate time.Time
 	EndDate time.Time
 }
@@ -526,8 +813,9 @@ type TaskTimeData struct {
 	TotalTime time.Duration
 }
 
-// collectTimeData gathers all time entries for the specified period
-func (s *Service) collectTimeData(period string) (*TimeReportData, error) {
+// collectTimeData gathers all time entries for the specified period. When
+// people are given, only tasks assigned to one of them are included.
+func (s *Service) collectTimeData(period string, people []string) (*TimeReportData, error) {
 	now := time.Now()
 	var startDate, endDate time.Time
 	
@@ -558,55 +846,53 @@ func (s *Service) collectTimeData(period string) (*TimeReportData, error) {
 		EndDate: endDate,
 	}
 	
-	// Collect all tasks with time entries
-	searchDirs := []string{"daily", "projects", "meetings", "design", "learning", "todos"}
+	// A first name only stands for a full name if it is unambiguous
+	var known roster
+	if len(people) > 0 {
+		var tasks []TaskInfo
+		s.walkNotes(func(path string) {
+			tasks = append(tasks, s.extractTasks(path)...)
+		})
+		known = rosterOf(tasks, people...)
+	}
 	
-	for _, dir := range searchDirs {
-		dirPath := filepath.Join(s.config.BaseDir, dir)
-		
-		err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
+	// Collect all tasks with time entries, whatever their status; most
+	// logged time ends up on tasks that are done by now
+	s.walkNotes(func(path string) {
+		for _, task := range s.extractTasks(path) {
+			if len(task.TimeEntries) == 0 {
+				continue
 			}
-			
-			if d.IsDir() || (!strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, ".txt")) {
-				return nil
+			if len(people) > 0 && !task.isAssignedToAny(people, known) {
+				continue
 			}
 			
-			tasks := s.extractTasks(path)
-			for _, task := range tasks {
-				if len(task.TimeEntries) == 0 {
-					continue
-				}
-				
-				// Filter time entries for the period
-				var filteredEntries []TimeEntry
-				var taskTotal time.Duration
-				
-				for _, entry := range task.TimeEntries {
-					if (entry.Date.After(startDate) || entry.Date.Equal(startDate)) && entry.Date.Before(endDate) {
-						filteredEntries = append(filteredEntries, entry)
-						taskTotal += entry.Duration
-					}
-				}
-				
-				if len(filteredEntries) > 0 {
-					report.Tasks = append(report.Tasks, TaskTimeData{
-						TaskInfo: task,
-						Entries: filteredEntries,
-						TotalTime: taskTotal,
-					})
-					report.TotalTime += taskTotal
+			// Filter time entries for the period
+			var filteredEntries []TimeEntry
+			var taskTotal time.Duration
+			
+			var entries []TimeEntry
+			for _, entry := range task.TimeEntries {
+				// Time past midnight counts towards the day it was worked
+				entries = append(entries, splitAtMidnight(entry)...)
+			}
+			for _, entry := range entries {
+				if (entry.Date.After(startDate) || entry.Date.Equal(startDate)) && entry.Date.Before(endDate) {
+					filteredEntries = append(filteredEntries, entry)
+					taskTotal += entry.Duration
 				}
 			}
 			
-			return nil
-		})
-		
-		if err != nil {
-			continue
+			if len(filteredEntries) > 0 {
+				report.Tasks = append(report.Tasks, TaskTimeData{
+					TaskInfo: task,
+					Entries: filteredEntries,
+					TotalTime: taskTotal,
+				})
+				report.TotalTime += taskTotal
+			}
 		}
-	}
+	})
 	
 	// Sort tasks by total time (descending)
 	sort.Slice(report.Tasks, func(i, j int) bool {
@@ -616,6 +902,42 @@ func (s *Service) collectTimeData(period string) (*TimeReportData, error) {
 	return report, nil
 }
 
+// writeTimeCSV writes every session in a report as a CSV row, oldest
+// first, for invoicing or spreadsheets
+func (s *Service) writeTimeCSV(report *TimeReportData, out io.Writer) error {
+	type row struct {
+		entry TimeEntry
+		task  TaskInfo
+	}
+	var rows []row
+	for _, taskData := range report.Tasks {
+		for _, entry := range taskData.Entries {
+			rows = append(rows, row{entry, taskData.TaskInfo})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].entry.StartTime.Before(rows[j].entry.StartTime)
+	})
+	
+	w := csv.NewWriter(out)
+	w.Write([]string{"date", "start", "end", "hours", "task", "description", "assignees", "note"})
+	for _, r := range rows {
+		relPath, _ := filepath.Rel(s.config.BaseDir, r.task.FilePath)
+		w.Write([]string{
+			r.entry.Date.Format("2006-01-02"),
+			r.entry.StartTime.Format("15:04"),
+			r.entry.EndTime.Format("15:04"),
+			strconv.FormatFloat(r.entry.Duration.Hours(), 'f', 2, 64),
+			r.task.Text,
+			r.entry.Description,
+			strings.Join(r.task.Assignees, " "),
+			relPath,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
 // formatTimeReport formats the time report for display
 func (s *Service) formatTimeReport(report *TimeReportData) {
 	// Header
@@ -628,6 +950,14 @@ func (s *Service) formatTimeReport(report *TimeReportData) {
 		periodTitle = "This Month"
 	}
 	
+	switch report.Person {
+	case "":
+	case "me":
+		periodTitle += " (your tasks)"
+	default:
+		periodTitle += " (@" + report.Person + "'s tasks)"
+	}
+	
 	fmt.Printf("\033[1;36m⏰ Time Report - %s\033[0m\n", periodTitle)
 	fmt.Printf("\033[90m%s to %s\033[0m\n", 
 		report.StartDate.Format("Jan 2"), 
@@ -651,10 +981,7 @@ func (s *Service) formatTimeReport(report *TimeReportData) {
 		relPath, _ := filepath.Rel(s.config.BaseDir, taskData.TaskInfo.FilePath)
 		
 		// Task header
-		taskDisplay := taskData.TaskInfo.Text
-		if len(taskDisplay) > 50 {
-			taskDisplay = taskDisplay[:47] + "..."
-		}
+		taskDisplay := truncateText(taskData.TaskInfo.Text, 50)
 		
 		percentage := float64(taskData.TotalTime) / float64(report.TotalTime) * 100
 		
@@ -736,73 +1063,95 @@ func (s *Service) calculateDailyAverage(report *TimeReportData) string {
 	return formatDuration(avgDuration)
 }
 
-// addTimeEntry adds a time entry to a task in its markdown file
+// addTimeEntry adds a time entry to a task in its markdown file. The task
+// is found again by its ID (or exact text) rather than the line recorded
+// when the timer started, since the file may have changed since then.
 func (s *Service) addTimeEntry(state TimerState, duration time.Duration) error {
-	// Read the file
-	content, err := os.ReadFile(state.FilePath)
+	task, err := s.locateTask(state.FilePath, state.TaskID, state.TaskText)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w; time was not logged", err)
 	}
 	
-	lines := strings.Split(string(content), "\n")
-	if state.TaskLine > len(lines) {
-		return fmt.Errorf("task line %d not found in file", state.TaskLine)
+	description := state.Description
+	if description == "" {
+		description = defaultSessionDescription
+	}
+	
+	// The log keeps whole minutes, or seconds for precise entries, so the
+	// duration stored is the one written
+	unit := time.Minute
+	if s.config.Time.Precise {
+		unit = time.Second
 	}
 	
 	// Create time entry
+	start := state.StartTime.Truncate(time.Second)
 	entry := TimeEntry{
-		Date:        state.StartTime,
-		StartTime:   state.StartTime,
-		EndTime:     state.StartTime.Add(duration),
-		Duration:    duration,
-		Description: "Work session", // Could be made configurable
+		Date:        startOfDay(start),
+		StartTime:   start,
+		EndTime:     start.Add(duration.Truncate(time.Second)),
+		Duration:    duration.Truncate(unit),
+		Description: description,
+		Seconds:     s.config.Time.Precise,
+		Zone:        s.config.Time.Precise,
 	}
 	
-	// Find insertion point (after the task line)
-	insertLine := state.TaskLine
+	return s.logTimeEntry(task, entry)
+}
+
+// insertTimeEntry writes an entry into a task's time log, creating the
+// "Time log:" block directly under the task if it has none yet
+func (s *Service) insertTimeEntry(task *TaskInfo, entry TimeEntry) error {
+	lines, err := readLines(task.FilePath)
+	if err != nil {
+		return err
+	}
 	
-	// Look for existing "Time log:" line
+	taskIndex := task.Line - 1
+	if taskIndex < 0 || taskIndex >= len(lines) {
+		return fmt.Errorf("task line %d not found in file", task.Line)
+	}
+	
+	detailIndent := strings.Repeat(" ", task.Indent+2)
+	
+	// Look for an existing "Time log:" among the task's indented detail lines
+	insertLine := taskIndex + 1
 	timeLogExists := false
-	for i := state.TaskLine; i < len(lines) && i < state.TaskLine+10; i++ {
-		if strings.Contains(lines[i], "Time log:") {
+	later := false
+	for i := taskIndex + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		
+		// Stop at a blank line, outdented content or a subtask
+		if trimmed == "" || indentOf(line) <= task.Indent || taskLinePattern.MatchString(line) {
+			break
+		}
+		
+		if trimmed == "Time log:" {
 			timeLogExists = true
-			// Find where to insert new entry (before "Remaining:" or "Total:" if they exist)
 			insertLine = i + 1
-			for j := i + 1; j < len(lines) && strings.HasPrefix(lines[j], "  "); j++ {
-				if strings.Contains(lines[j], "Remaining:") || strings.Contains(lines[j], "Total:") {
-					insertLine = j
-					break
-				}
-				if strings.HasPrefix(lines[j], "  •") {
-					insertLine = j + 1
-				}
-			}
-			break
+			continue
 		}
-		// Stop if we hit another task or non-indented content
-		if !strings.HasPrefix(lines[i], "  ") && strings.TrimSpace(lines[i]) != "" && i > state.TaskLine {
-			break
+		// New entries go after the last entry, before "Remaining:" or "Total:",
+		// unless time logged after the fact belongs before a later entry
+		if timeLogExists && timeEntryLinePattern.MatchString(line) && !later {
+			if existing, err := parseTimeEntry(line); err == nil && existing.StartTime.After(entry.StartTime) {
+				later = true
+				continue
+			}
+			insertLine = i + 1
 		}
 	}
 	
-	// Insert time log block if it doesn't exist
+	newBlock := []string{detailIndent + formatTimeEntry(entry)}
 	if !timeLogExists {
-		newLines := make([]string, 0, len(lines)+3)
-		newLines = append(newLines, lines[:insertLine]...)
-		newLines = append(newLines, "  Time log:")
-		newLines = append(newLines, formatTimeEntry(entry))
-		newLines = append(newLines, lines[insertLine:]...)
-		lines = newLines
-	} else {
-		// Insert just the time entry
-		newLines := make([]string, 0, len(lines)+1)
-		newLines = append(newLines, lines[:insertLine]...)
-		newLines = append(newLines, formatTimeEntry(entry))
-		newLines = append(newLines, lines[insertLine:]...)
-		lines = newLines
-	}
-	
-	// Write back to file
-	newContent := strings.Join(lines, "\n")
-	return os.WriteFile(state.FilePath, []byte(newContent), 0644)
-}
\ No newline at end of file
+		newBlock = append([]string{detailIndent + "Time log:"}, newBlock...)
+	}
+	
+	newLines := make([]string, 0, len(lines)+len(newBlock))
+	newLines = append(newLines, lines[:insertLine]...)
+	newLines = append(newLines, newBlock...)
+	newLines = append(newLines, lines[insertLine:]...)
+	
+	return writeLines(task.FilePath, newLines)
+}
@@ -0,0 +1,159 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestTimeEntryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string // the line formatTimeEntry writes back, if it differs
+	}{
+		{name: "timer entry", line: "  • 2024-01-15 09:30-10:45 (1h15m) - Initial component setup"},
+		{name: "hand-written bullet", line: "    * 2024-01-15 09:30-10:45 (1h15m) - Review"},
+		{name: "minutes only", line: "  - 2024-01-15 09:30-10:45 (75m) - Review", want: "  - 2024-01-15 09:30-10:45 (1h15m) - Review"},
+		{name: "dash in description", line: "• 2024-01-15 09:30-10:00 (30m) - fix a - b"},
+		{name: "ends at midnight", line: "• 2024-01-15 23:30-00:00 (30m) - Late"},
+		{name: "legacy past midnight", line: "• 2024-01-15 23:30-00:45 (1h15m) - Release night", want: "• 2024-01-15 23:30 → 2024-01-16 00:45 (1h15m) - Release night"},
+		{name: "across days", line: "• 2024-01-15 23:30 → 2024-01-16 00:45 (1h15m) - Release night"},
+		{name: "whole day", line: "• 2024-01-16 00:00 → 2024-01-17 00:00 (24h) - Offsite"},
+		{name: "seconds", line: "• 2024-01-16 09:00:15-09:30:45 (30m30s) - Standup"},
+		{name: "seconds, whole minutes", line: "• 2024-01-16 09:00:15-09:30:15 (30m) - Standup"},
+		{name: "utc offset", line: "• 2024-01-16 14:00+02:00-15:00+02:00 (1h) - Call from Berlin"},
+		{name: "precise across days", line: "• 2024-01-15 23:30:27Z → 2024-01-16 00:45:54Z (1h15m27s) - Deploy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := parseTimeEntry(tt.line)
+			if err != nil {
+				t.Fatalf("parseTimeEntry(%q): %v", tt.line, err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.line
+			}
+			got := formatTimeEntry(*entry)
+			if got != want {
+				t.Fatalf("formatTimeEntry = %q, want %q", got, want)
+			}
+
+			again, err := parseTimeEntry(got)
+			if err != nil {
+				t.Fatalf("parseTimeEntry(%q): %v", got, err)
+			}
+			if !again.StartTime.Equal(entry.StartTime) || !again.EndTime.Equal(entry.EndTime) || again.Duration != entry.Duration {
+				t.Errorf("%q reads back as %s-%s (%s), want %s-%s (%s)", got,
+					again.StartTime, again.EndTime, again.Duration, entry.StartTime, entry.EndTime, entry.Duration)
+			}
+			if again.Description != entry.Description {
+				t.Errorf("description = %q, want %q", again.Description, entry.Description)
+			}
+		})
+	}
+}
+
+func TestParseTimeEntryInvalid(t *testing.T) {
+	for _, line := range []string{
+		"• 2024-01-15 09:30 (1h) - No end",
+		"• 2024-01-15 09:30-10:30 - No duration",
+		"• 2024-01-15 09:30-10:30 (soon) - Bad duration",
+		"• 2024-01-16 00:45 → 2024-01-15 23:30 (1h) - Backwards",
+		"Time log:",
+	} {
+		if entry, err := parseTimeEntry(line); err == nil {
+			t.Errorf("parseTimeEntry(%q) = %+v, want an error", line, entry)
+		}
+	}
+}
+
+func TestSplitAtMidnight(t *testing.T) {
+	at := func(day, hour, minute, second int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, second, 0, time.Local)
+	}
+	tests := []struct {
+		name      string
+		start     time.Time
+		end       time.Time
+		duration  time.Duration
+		seconds   bool
+		durations []string
+	}{
+		{name: "same day", start: at(15, 9, 30, 0), end: at(15, 10, 45, 0), duration: 75 * time.Minute, durations: []string{"1h15m"}},
+		{name: "ends at midnight", start: at(15, 23, 30, 0), end: at(16, 0, 0, 0), duration: 30 * time.Minute, durations: []string{"30m"}},
+		{name: "past midnight", start: at(15, 23, 30, 0), end: at(16, 0, 45, 0), duration: 75 * time.Minute, durations: []string{"30m", "45m"}},
+		{name: "paused past midnight", start: at(15, 23, 30, 27), end: at(16, 0, 45, 54), duration: 75 * time.Minute, durations: []string{"29m", "46m"}},
+		{name: "precise", start: at(15, 23, 30, 27), end: at(16, 0, 45, 54), duration: 75*time.Minute + 27*time.Second, seconds: true, durations: []string{"29m33s", "45m54s"}},
+		{name: "several days", start: at(15, 22, 0, 0), end: at(17, 2, 0, 0), duration: 28 * time.Hour, durations: []string{"2h", "24h", "2h"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := TimeEntry{
+				Date:        startOfDay(tt.start),
+				StartTime:   tt.start,
+				EndTime:     tt.end,
+				Duration:    tt.duration,
+				Description: "Work session",
+				Seconds:     tt.seconds,
+			}
+			parts := splitAtMidnight(entry)
+
+			var durations []string
+			var logged time.Duration
+			for i, part := range parts {
+				durations = append(durations, formatEntryDuration(part))
+
+				// What is written must read back as what was meant
+				line := formatTimeEntry(part)
+				read, err := parseTimeEntry(line)
+				if err != nil {
+					t.Fatalf("part %d: parseTimeEntry(%q): %v", i, line, err)
+				}
+				logged += read.Duration
+				if !startOfDay(read.StartTime).Equal(startOfDay(read.EndTime.Add(-time.Nanosecond))) {
+					t.Errorf("part %d (%q) spans more than one day", i, line)
+				}
+			}
+
+			if got := strings.Join(durations, " + "); got != strings.Join(tt.durations, " + ") {
+				t.Errorf("durations = %s, want %s", got, strings.Join(tt.durations, " + "))
+			}
+			if logged != tt.duration {
+				t.Errorf("parts add up to %s, want %s", logged, tt.duration)
+			}
+			if !parts[0].StartTime.Equal(tt.start) || !parts[len(parts)-1].EndTime.Equal(tt.end) {
+				t.Errorf("parts cover %s-%s, want %s-%s", parts[0].StartTime, parts[len(parts)-1].EndTime, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{text: "Short", max: 10, want: "Short"},
+		{text: "Exactly10!", max: 10, want: "Exactly10!"},
+		{text: "Eleven char", max: 10, want: "Eleven ..."},
+		{text: "Überprüfung der Zahlen", max: 10, want: "Überprü..."},
+		{text: "日本語のタスクを完了する", max: 8, want: "日本語のタ..."},
+		{text: "Plan 🎉🎉🎉🎉🎉", max: 8, want: "Plan ..."},
+	}
+
+	for _, tt := range tests {
+		got := truncateText(tt.text, tt.max)
+		if got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateText(%q, %d) = %q is not valid UTF-8", tt.text, tt.max, got)
+		}
+	}
+}
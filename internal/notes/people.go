@@ -0,0 +1,181 @@
+package notes
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"notes/internal/frontmatter"
+)
+
+var (
+	// mentionPattern matches an @name mention. Email addresses don't match
+	// because the @ must start a word.
+	mentionPattern = regexp.MustCompile(`(?:^|\s)@([\p{L}\p{N}_][\p{L}\p{N}_.-]*)`)
+	// ownerPrefixPattern matches the "Name:" or "First Last:" that starts an
+	// action item in meeting notes
+	ownerPrefixPattern = regexp.MustCompile(`^([\p{L}][\p{L}.'-]*(?: [\p{L}][\p{L}.'-]*)?)\s*:\s+\S`)
+)
+
+// parseMentions returns the people @mentioned in task text, in order and
+// without repeats. Only the same handle counts as a repeat: @alice and
+// @alice-jones may be two people.
+func parseMentions(text string) []string {
+	var people []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(match[1], ".-")
+		if name == "" || containsPerson(people, name) {
+			continue
+		}
+		people = append(people, name)
+	}
+	return people
+}
+
+// normalizePerson reduces a name to lower-case letters and digits, so
+// @alice-smith, @AliceSmith and "Alice Smith" compare equal
+func normalizePerson(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// samePerson reports whether two ways of writing a name are the same
+// name, such as @alice-smith and "Alice Smith"
+func samePerson(a, b string) bool {
+	na, nb := normalizePerson(a), normalizePerson(b)
+	return na != "" && na == nb
+}
+
+func firstName(name string) string {
+	if fields := strings.FieldsFunc(name, func(r rune) bool { return r == ' ' || r == '.' || r == '-' || r == '_' }); len(fields) > 0 {
+		return fields[0]
+	}
+	return name
+}
+
+func containsPerson(people []string, name string) bool {
+	for _, person := range people {
+		if samePerson(person, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// roster is every name used for someone in the vault: assignees, meeting
+// attendees and the current user. It decides whether a first name on its
+// own is unambiguous.
+type roster []string
+
+// rosterOf returns the names assigned to tasks, plus extra names such as
+// the ones returned by myNames
+func rosterOf(tasks []TaskInfo, extra ...string) roster {
+	names := append(roster{}, extra...)
+	for _, task := range tasks {
+		names = append(names, task.Assignees...)
+	}
+	return names
+}
+
+// refersTo reports whether name means person. Besides the same name, a
+// first name on its own means a full name when no one else in the roster
+// has that first name, so @alice is "Alice Smith" unless there is also an
+// Alice Jones.
+func (r roster) refersTo(name, person string) bool {
+	if samePerson(name, person) {
+		return true
+	}
+
+	short, full := name, person
+	if normalizePerson(firstName(name)) == normalizePerson(person) {
+		short, full = person, name
+	}
+	first := normalizePerson(short)
+	if first == "" || normalizePerson(firstName(full)) != first {
+		return false
+	}
+
+	fullNames := map[string]bool{normalizePerson(full): true}
+	for _, known := range r {
+		if normalized := normalizePerson(known); normalized != first && normalizePerson(firstName(known)) == first {
+			fullNames[normalized] = true
+		}
+	}
+	return len(fullNames) == 1
+}
+
+// IsAssignedTo reports whether a task is assigned to person, with the
+// roster settling what a first name on its own means
+func (t TaskInfo) IsAssignedTo(person string, r roster) bool {
+	person = strings.TrimPrefix(person, "@")
+	for _, assignee := range t.Assignees {
+		if r.refersTo(person, assignee) {
+			return true
+		}
+	}
+	return false
+}
+
+// myNames returns the names that mean "me" in assignments: the user.handle
+// setting and the user name (user.name, else git's user.name)
+func (s *Service) myNames() []string {
+	var names []string
+	if s.config.User.Handle != "" {
+		names = append(names, strings.TrimPrefix(s.config.User.Handle, "@"))
+	}
+	if name := s.userName(); name != "" {
+		names = append(names, name)
+	}
+	return names
+}
+
+// isAssignedToAny reports whether a task is assigned to any of the names,
+// such as the ones returned by myNames
+func (t TaskInfo) isAssignedToAny(names []string, r roster) bool {
+	for _, name := range names {
+		if t.IsAssignedTo(name, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// meetingAttendees returns the attendees of a meeting note, or nil if the
+// note isn't a meeting. Action items in it are attributed to them.
+func (s *Service) meetingAttendees(filePath string, fm *frontmatter.Frontmatter) []string {
+	isMeeting := strings.EqualFold(fm.String("type"), string(Meeting))
+	if nt, ok := s.config.NoteType(string(Meeting)); ok && !isMeeting {
+		dir := filepath.Join(s.config.BaseDir, nt.Dir) + string(filepath.Separator)
+		isMeeting = strings.HasPrefix(filePath, dir)
+	}
+	if !isMeeting {
+		return nil
+	}
+
+	var attendees []string
+	for _, name := range fm.Strings("attendees") {
+		attendees = append(attendees, strings.TrimPrefix(name, "@"))
+	}
+	return attendees
+}
+
+// actionItemOwner returns the attendee an action item starts with, as in
+// "- [ ] Bob: send the deck", or "" if it doesn't name one
+func actionItemOwner(text string, attendees []string) string {
+	match := ownerPrefixPattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	for _, attendee := range attendees {
+		if roster(attendees).refersTo(match[1], attendee) {
+			return attendee
+		}
+	}
+	return ""
+}
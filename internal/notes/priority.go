@@ -0,0 +1,242 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Priority is how important a task is. Tasks without a priority marker are
+// low priority.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityMedium Priority = "medium"
+	PriorityLow    Priority = "low"
+)
+
+var (
+	// priorityMarkerPattern matches !1 (high), !2 (medium) and !3 (low)
+	priorityMarkerPattern = regexp.MustCompile(`(^|\s)!([1-3])(\s|$)`)
+	// priorityTokenPattern matches p:high, p:medium and p:low
+	priorityTokenPattern = regexp.MustCompile(`(^|\s)p:(\S+)`)
+	// priorityLetterPattern matches a todo.txt style (A), (B) or (C) at the
+	// start of the task text
+	priorityLetterPattern = regexp.MustCompile(`^\(([A-C])\)(\s|$)`)
+)
+
+// parsePriority reads a priority written as a word, an abbreviation or a
+// number: high/h/1, medium/med/m/2, low/l/3
+func parsePriority(value string) (Priority, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "high", "h", "1", "a":
+		return PriorityHigh, true
+	case "medium", "med", "m", "2", "b":
+		return PriorityMedium, true
+	case "low", "l", "3", "c":
+		return PriorityLow, true
+	}
+	return "", false
+}
+
+// ValidatePriorityFilter checks a --priority value
+func ValidatePriorityFilter(value string) error {
+	if _, ok := parsePriority(value); !ok {
+		return fmt.Errorf("invalid priority: %s. Use one of: high, medium, low", value)
+	}
+	return nil
+}
+
+// Marker returns the marker written on task lines for a priority
+func (p Priority) Marker() string {
+	switch p {
+	case PriorityHigh:
+		return "!1"
+	case PriorityMedium:
+		return "!2"
+	default:
+		return "!3"
+	}
+}
+
+// Emoji returns the dot shown next to tasks of a priority
+func (p Priority) Emoji() string {
+	switch p {
+	case PriorityHigh:
+		return "🔴"
+	case PriorityMedium:
+		return "🟡"
+	default:
+		return "⚪"
+	}
+}
+
+// rank orders priorities for sorting, highest first
+func (p Priority) rank() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// explicitPriority finds a priority marker in task text and returns the
+// priority and the text without its markers. ok is false when the text has
+// no valid marker.
+func explicitPriority(text string) (priority Priority, rest string, ok bool) {
+	rest = text
+	if match := priorityLetterPattern.FindStringSubmatch(rest); match != nil {
+		priority, ok = parsePriority(match[1])
+		rest = priorityLetterPattern.ReplaceAllString(rest, "")
+	}
+	if match := priorityMarkerPattern.FindStringSubmatch(rest); match != nil {
+		priority, ok = parsePriority(match[2])
+		rest = priorityMarkerPattern.ReplaceAllString(rest, "$1")
+	}
+	if match := priorityTokenPattern.FindStringSubmatch(rest); match != nil {
+		if p, valid := parsePriority(match[2]); valid {
+			priority, ok = p, true
+			rest = priorityTokenPattern.ReplaceAllString(rest, "")
+		}
+	}
+	return priority, rest, ok
+}
+
+// taskPriority works out the priority of task text: an explicit marker if
+// it has one, else a guess from the configured keywords when the vault
+// opts in to that, else low. The text is returned without its markers.
+func (s *Service) taskPriority(text string) (Priority, string) {
+	if priority, rest, ok := explicitPriority(text); ok {
+		return priority, rest
+	}
+	if s.config.Priority.InferKeywords {
+		return s.inferPriority(text), text
+	}
+	return PriorityLow, text
+}
+
+// inferPriority guesses a priority from the high and medium keywords in
+// the vault config
+func (s *Service) inferPriority(taskText string) Priority {
+	taskLower := strings.ToLower(taskText)
+
+	for _, keyword := range s.config.Priority.High {
+		if strings.Contains(taskLower, strings.ToLower(keyword)) {
+			return PriorityHigh
+		}
+	}
+
+	for _, keyword := range s.config.Priority.Medium {
+		if strings.Contains(taskLower, strings.ToLower(keyword)) {
+			return PriorityMedium
+		}
+	}
+
+	return PriorityLow
+}
+
+// setTaskPriority replaces any priority markers on a task line with the
+// marker for priority, or just removes them when priority is empty
+func setTaskPriority(line string, priority Priority) string {
+	match := taskLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return line
+	}
+
+	_, text, _ := explicitPriority(strings.TrimSpace(match[3]))
+	line = strings.TrimRight(taskMarkPattern.FindString(line)+" "+strings.TrimSpace(text), " ")
+	if priority == "" {
+		return line
+	}
+	return appendTaskToken(line, priority.Marker())
+}
+
+// MigratePriorities writes the priority that keyword inference gives each
+// unfinished task as an explicit marker, so it survives turning inference
+// off. Low priority tasks are left unmarked, and so are checkboxes in
+// frontmatter and code blocks, which aren't tasks.
+func (s *Service) MigratePriorities(dryRun bool) error {
+	changed := 0
+
+	s.walkNotes(func(path string) {
+		lines, err := readLines(path)
+		if err != nil {
+			return
+		}
+
+		relPath, _ := filepath.Rel(s.config.BaseDir, path)
+		noteText := noteTextLines(lines)
+		fileChanged := false
+		for i, line := range lines {
+			match := taskLinePattern.FindStringSubmatch(line)
+			if match == nil || !noteText[i] {
+				continue
+			}
+			status := statusMarks[match[2]]
+			if status == StatusDone || status == StatusCancelled {
+				continue
+			}
+			text := strings.TrimSpace(match[3])
+			if _, _, ok := explicitPriority(text); ok {
+				continue
+			}
+			priority := s.inferPriority(text)
+			if priority == PriorityLow {
+				continue
+			}
+
+			updated := appendTaskToken(line, priority.Marker())
+			fmt.Printf("\033[1;34m%s:L%d\033[0m\n", relPath, i+1)
+			fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(line))
+			fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(updated))
+			lines[i] = updated
+			fileChanged = true
+			changed++
+		}
+
+		if fileChanged && !dryRun {
+			if err := writeLines(path, lines); err != nil {
+				fmt.Printf("\033[1;31m✗ %s: %v\033[0m\n", relPath, err)
+			}
+		}
+	})
+
+	switch {
+	case changed == 0:
+		fmt.Printf("✅ No keyword priorities left to migrate\n")
+	case dryRun:
+		fmt.Printf("\n\033[90m%d task%s would get a priority marker (dry run)\033[0m\n", changed, pluralize(changed))
+	default:
+		fmt.Printf("\n✅ Added priority markers to %d task%s\n", changed, pluralize(changed))
+	}
+	if changed > 0 && s.config.Priority.InferKeywords {
+		fmt.Printf("\033[90mKeyword inference is still on; turn it off with: notes config set priority.infer_keywords false\033[0m\n")
+	}
+	return nil
+}
+
+// HandlePriorityCommand processes notes priority subcommands
+func (s *Service) HandlePriorityCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("priority command requires a subcommand")
+	}
+
+	switch args[0] {
+	case "migrate":
+		dryRun := false
+		for _, arg := range args[1:] {
+			if arg != "--dry-run" && arg != "-n" {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			dryRun = true
+		}
+		return s.MigratePriorities(dryRun)
+	default:
+		return fmt.Errorf("unknown priority command: %s", args[0])
+	}
+}
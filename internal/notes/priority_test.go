@@ -0,0 +1,85 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplicitPriority(t *testing.T) {
+	tests := []struct {
+		text     string
+		priority Priority
+		rest     string
+		ok       bool
+	}{
+		{text: "Ship the release !1", priority: PriorityHigh, rest: "Ship the release ", ok: true},
+		{text: "(B) Book flights", priority: PriorityMedium, rest: "Book flights", ok: true},
+		{text: "Tidy the desk p:low", priority: PriorityLow, rest: "Tidy the desk", ok: true},
+		{text: "Call the vendor p:h #work", priority: PriorityHigh, rest: "Call the vendor #work", ok: true},
+		{text: "Urgent: fix the build", rest: "Urgent: fix the build"},
+		{text: "Read chapter !4", rest: "Read chapter !4"},
+		{text: "Pick p:maybe", rest: "Pick p:maybe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			priority, rest, ok := explicitPriority(tt.text)
+			if priority != tt.priority || rest != tt.rest || ok != tt.ok {
+				t.Errorf("explicitPriority(%q) = %q, %q, %v, want %q, %q, %v",
+					tt.text, priority, rest, ok, tt.priority, tt.rest, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMigratePriorities(t *testing.T) {
+	note := lines(
+		"- [ ] Urgent: fix the build",
+		"- [ ] Reply soon to Ann",
+		"- [ ] Water plants",
+		"- [ ] Urgent call !3",
+		"- [x] Urgent, but done",
+		"Urgent, not a task")
+
+	s := newTestVault(t, map[string]string{"todos/work.md": note})
+	if err := s.HandlePriorityCommand([]string{"migrate", "--dry-run"}); err != nil {
+		t.Fatalf("priority migrate --dry-run: %v", err)
+	}
+	if got := readNote(t, s, "todos/work.md"); got != note {
+		t.Errorf("dry run changed the note:\n%s", got)
+	}
+
+	if err := s.HandlePriorityCommand([]string{"migrate"}); err != nil {
+		t.Fatalf("priority migrate: %v", err)
+	}
+	want := lines(
+		"- [ ] Urgent: fix the build !1",
+		"- [ ] Reply soon to Ann !2",
+		"- [ ] Water plants",
+		"- [ ] Urgent call !3",
+		"- [x] Urgent, but done",
+		"Urgent, not a task")
+	if got := readNote(t, s, "todos/work.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMigratePrioritiesSkipsCode(t *testing.T) {
+	note := lines(
+		"---",
+		"checklist: |",
+		"  - [ ] Urgent review",
+		"---",
+		"```markdown",
+		"- [ ] Urgent example",
+		"```",
+		"- [ ] Urgent fix")
+	s := newTestVault(t, map[string]string{"todos/work.md": note})
+	if err := s.HandlePriorityCommand([]string{"migrate"}); err != nil {
+		t.Fatalf("priority migrate: %v", err)
+	}
+	want := strings.Replace(note, "- [ ] Urgent fix", "- [ ] Urgent fix !1", 1)
+	if got := readNote(t, s, "todos/work.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,226 @@
+package notes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrencePattern matches the every: token on a task line
+var recurrencePattern = regexp.MustCompile(`every:(\S+)`)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Recurrence is a parsed every: rule
+type Recurrence struct {
+	Rule     string
+	Days     int                   // every N days
+	Months   int                   // every N months
+	Weekdays map[time.Weekday]bool // on these weekdays
+	MonthDay int                   // on this day of the month (with Months)
+}
+
+// parseRecurrence parses an every: rule. Supported rules are daily,
+// weekly, monthly, yearly, weekdays, a list of days (mon,thu), an interval
+// (3d, 2w, 6m, 1y) and monthly:N for day N of each month.
+func parseRecurrence(rule string) (*Recurrence, error) {
+	r := &Recurrence{Rule: rule}
+	value := strings.ToLower(rule)
+
+	switch value {
+	case "daily", "day":
+		r.Days = 1
+		return r, nil
+	case "weekly", "week":
+		r.Days = 7
+		return r, nil
+	case "monthly", "month":
+		r.Months = 1
+		return r, nil
+	case "yearly", "year", "annually":
+		r.Months = 12
+		return r, nil
+	case "weekdays", "weekday":
+		r.Weekdays = map[time.Weekday]bool{
+			time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true,
+		}
+		return r, nil
+	}
+
+	if day, ok := strings.CutPrefix(value, "monthly:"); ok {
+		n, err := strconv.Atoi(day)
+		if err != nil || n < 1 || n > 31 {
+			return nil, fmt.Errorf("invalid recurrence every:%s (day of month must be 1-31)", rule)
+		}
+		r.Months = 1
+		r.MonthDay = n
+		return r, nil
+	}
+
+	if len(value) >= 2 {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n > 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				r.Days = n
+				return r, nil
+			case 'w':
+				r.Days = 7 * n
+				return r, nil
+			case 'm':
+				r.Months = n
+				return r, nil
+			case 'y':
+				r.Months = 12 * n
+				return r, nil
+			}
+		}
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		day, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid recurrence every:%s (use daily, weekly, monthly, yearly, weekdays, mon,thu, 2w or monthly:15)", rule)
+		}
+		days[day] = true
+	}
+	r.Weekdays = days
+	return r, nil
+}
+
+// after returns the first occurrence strictly after date
+func (r *Recurrence) after(date time.Time) time.Time {
+	switch {
+	case r.Weekdays != nil:
+		next := date.AddDate(0, 0, 1)
+		for !r.Weekdays[next.Weekday()] {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	case r.MonthDay > 0:
+		next := onMonthDay(date.Year(), date.Month(), r.MonthDay, date.Location())
+		if !next.After(date) {
+			next = onMonthDay(date.Year(), date.Month()+time.Month(r.Months), r.MonthDay, date.Location())
+		}
+		return next
+	case r.Months > 0:
+		return onMonthDay(date.Year(), date.Month()+time.Month(r.Months), date.Day(), date.Location())
+	default:
+		return date.AddDate(0, 0, r.Days)
+	}
+}
+
+// Next returns the next due date for a task completed on today. It steps
+// forward from the task's due date (or today if it has none) until the
+// date is after today, so missed occurrences are skipped rather than
+// created in the past.
+func (r *Recurrence) Next(due *time.Time, today time.Time) time.Time {
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	base := today
+	if due != nil {
+		base = time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, today.Location())
+	}
+
+	next := r.after(base)
+	for !next.After(today) {
+		next = r.after(next)
+	}
+	return next
+}
+
+// onMonthDay returns day of the given month, clamped to the month's last
+// day so monthly:31 falls on Feb 28/29
+func onMonthDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, loc)
+}
+
+// insertNextOccurrence adds an unchecked copy of a recurring task below it
+// (after its time log and subtasks) with the next due date. Its start: and
+// scheduled: dates move by as many days as the due date. Dependencies and
+// IDs belong to the finished task, so the copy has none. It returns the new
+// due date.
+func (s *Service) insertNextOccurrence(task *TaskInfo, rule *Recurrence) (string, error) {
+	lines, err := readLines(task.FilePath)
+	if err != nil {
+		return "", err
+	}
+
+	index := task.Line - 1
+	if index < 0 || index >= len(lines) || !taskLinePattern.MatchString(lines[index]) {
+		return "", fmt.Errorf("task line %d changed; next occurrence not created", task.Line)
+	}
+
+	now := time.Now()
+	next := rule.Next(task.DueDate, now)
+	if task.DueHasTime {
+		// Recurring due times keep their time of day
+		next = next.Add(time.Duration(task.DueDate.Hour())*time.Hour + time.Duration(task.DueDate.Minute())*time.Minute)
+	}
+	nextDue := formatDate(next, task.DueHasTime)
+
+	// A task without a due date recurs from the day it was done
+	from := now
+	if task.DueDate != nil {
+		from = *task.DueDate
+	}
+	shift := daysUntil(next, from)
+
+	// The copy is a new task: it starts unchecked, gets its own ID later
+	// and waits for nothing yet
+	copyLine := setTaskMark(lines[index], StatusTodo)
+	copyLine = strings.TrimRight(taskIDPattern.ReplaceAllString(copyLine, ""), " \t")
+	for _, key := range []string{"id", "after", "blocks"} {
+		copyLine = setTaskToken(copyLine, key, "")
+	}
+	for _, key := range []string{"start", "scheduled"} {
+		copyLine = shiftDateToken(copyLine, key, shift, now)
+	}
+	copyLine = setTaskToken(copyLine, "due", nextDue)
+
+	insertAt := index + 1
+	for insertAt < len(lines) {
+		line := lines[insertAt]
+		if strings.TrimSpace(line) == "" || indentOf(line) <= task.Indent {
+			break
+		}
+		insertAt++
+	}
+
+	newLines := make([]string, 0, len(lines)+1)
+	newLines = append(newLines, lines[:insertAt]...)
+	newLines = append(newLines, copyLine)
+	newLines = append(newLines, lines[insertAt:]...)
+	if err := writeLines(task.FilePath, newLines); err != nil {
+		return "", err
+	}
+	return nextDue, nil
+}
+
+// shiftDateToken moves the date of a key:date token on a task line by days,
+// keeping its time of day if it has one. Tokens that aren't dates are left
+// alone.
+func shiftDateToken(line, key string, days int, now time.Time) string {
+	match := regexp.MustCompile(`(^|\s)` + regexp.QuoteMeta(key) + `:(\S+)`).FindStringSubmatch(line)
+	if match == nil {
+		return line
+	}
+	date, hasTime, err := parseDate(match[2], now)
+	if err != nil {
+		return line
+	}
+	return setTaskToken(line, key, formatDate(date.AddDate(0, 0, days), hasTime))
+}
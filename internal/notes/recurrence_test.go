@@ -0,0 +1,119 @@
+package notes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	day := func(value string) *time.Time {
+		date, err := time.ParseInLocation("2006-01-02", value, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &date
+	}
+	tests := []struct {
+		rule  string
+		due   string // empty for a task without a due date
+		today string
+		want  string
+	}{
+		{rule: "daily", due: "2024-03-13", today: "2024-03-13", want: "2024-03-14"},
+		{rule: "daily", due: "2024-03-10", today: "2024-03-13", want: "2024-03-14"},
+		{rule: "weekly", due: "2024-03-13", today: "2024-03-13", want: "2024-03-20"},
+		{rule: "weekly", due: "2024-03-01", today: "2024-03-13", want: "2024-03-15"},
+		{rule: "2w", today: "2024-03-13", want: "2024-03-27"},
+		{rule: "3d", due: "2024-03-13", today: "2024-03-12", want: "2024-03-16"},
+		{rule: "weekdays", due: "2024-03-15", today: "2024-03-15", want: "2024-03-18"},
+		{rule: "mon,thu", today: "2024-03-13", want: "2024-03-14"},
+		{rule: "Monday,Thursday", due: "2024-03-14", today: "2024-03-14", want: "2024-03-18"},
+		{rule: "monthly", due: "2024-01-15", today: "2024-01-15", want: "2024-02-15"},
+		{rule: "monthly", due: "2024-01-31", today: "2024-01-31", want: "2024-02-29"},
+		{rule: "monthly:31", due: "2024-01-31", today: "2024-01-31", want: "2024-02-29"},
+		{rule: "monthly:15", today: "2024-03-13", want: "2024-03-15"},
+		{rule: "6m", due: "2024-03-13", today: "2024-03-13", want: "2024-09-13"},
+		{rule: "yearly", due: "2024-02-29", today: "2024-02-29", want: "2025-02-28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule+" "+tt.due, func(t *testing.T) {
+			rule, err := parseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("parseRecurrence(%q): %v", tt.rule, err)
+			}
+			var due *time.Time
+			if tt.due != "" {
+				due = day(tt.due)
+			}
+			if got := rule.Next(due, *day(tt.today)).Format("2006-01-02"); got != tt.want {
+				t.Errorf("every:%s from %q on %s = %s, want %s", tt.rule, tt.due, tt.today, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecurrenceInvalid(t *testing.T) {
+	for _, rule := range []string{"", "fortnightly", "0d", "-1w", "monthly:0", "monthly:32", "mon,someday"} {
+		if r, err := parseRecurrence(rule); err == nil {
+			t.Errorf("parseRecurrence(%q) = %+v, want an error", rule, r)
+		}
+	}
+}
+
+func TestCompleteRecurringTask(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"todos/home.md": lines(
+			"## Tasks",
+			"- [ ] Water plants every:weekly due:2099-01-07 #home",
+			"  - [ ] Fill the can",
+			"- [ ] Other"),
+	})
+	if err := s.HandleTaskCommand([]string{"done", "water plants"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+	want := lines(
+		"## Tasks",
+		"- [x] Water plants every:weekly due:2099-01-07 #home",
+		"  - [ ] Fill the can",
+		"- [ ] Water plants every:weekly #home due:2099-01-14",
+		"- [ ] Other")
+	if got := readNote(t, s, "todos/home.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNextOccurrenceShiftsDates(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"todos/home.md": lines(
+			"- [ ] Plan the week every:weekly start:2099-01-05 due:2099-01-07 scheduled:2099-01-06T09:00 after:t-prep blocks:t-review id:plan",
+			"- [ ] Prepare ^t-prep",
+			"- [ ] Review ^t-review"),
+		"todos/work.md": lines("- [ ] Send report every:2w due:2099-02-01 start:2099-01-30 ^t-report"),
+	})
+	if err := s.HandleTaskCommand([]string{"done", "prepare"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+
+	if err := s.HandleTaskCommand([]string{"done", "plan the week"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+	want := lines(
+		"- [x] Plan the week every:weekly start:2099-01-05 due:2099-01-07 scheduled:2099-01-06T09:00 after:t-prep blocks:t-review id:plan",
+		"- [ ] Plan the week every:weekly start:2099-01-12 scheduled:2099-01-13T09:00 due:2099-01-14",
+		"- [x] Prepare ^t-prep",
+		"- [ ] Review ^t-review")
+	if got := readNote(t, s, "todos/home.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := s.HandleTaskCommand([]string{"done", "^t-report"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+	want = lines(
+		"- [x] Send report every:2w due:2099-02-01 start:2099-01-30 ^t-report",
+		"- [ ] Send report every:2w start:2099-02-13 due:2099-02-15")
+	if got := readNote(t, s, "todos/work.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
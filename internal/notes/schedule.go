@@ -0,0 +1,106 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	startDatePattern = regexp.MustCompile(`\bstart:(\S+)`)
+	scheduledPattern = regexp.MustCompile(`\bscheduled:(\S+)`)
+	waitingPattern   = regexp.MustCompile(`\bwaiting:@?(\S+)`)
+)
+
+// IsUpcoming reports whether a task can't be started yet because its start
+// or scheduled date is after today. Default views hide these tasks.
+func (t TaskInfo) IsUpcoming(now time.Time) bool {
+	if t.StartDate != nil && daysUntil(*t.StartDate, now) > 0 {
+		return true
+	}
+	return t.Scheduled != nil && daysUntil(*t.Scheduled, now) > 0
+}
+
+// IsScheduledNow reports whether a task is scheduled for today or a day
+// that has passed, so it belongs in today's focus list
+func (t TaskInfo) IsScheduledNow(now time.Time) bool {
+	return t.Scheduled != nil && daysUntil(*t.Scheduled, now) <= 0
+}
+
+// IsWaiting reports whether a task is blocked on someone else
+func (t TaskInfo) IsWaiting() bool {
+	return t.WaitingOn != ""
+}
+
+// scheduleInfo describes a task's start, schedule and waiting state for
+// task listings
+func scheduleInfo(task TaskInfo, now time.Time) string {
+	var parts []string
+	if task.StartDate != nil && daysUntil(*task.StartDate, now) > 0 {
+		parts = append(parts, "starts "+formatRelativeTime(task.StartDate, false))
+	}
+	if task.Scheduled != nil {
+		parts = append(parts, "scheduled "+formatRelativeTime(task.Scheduled, false))
+	}
+
+	info := ""
+	if len(parts) > 0 {
+		info = fmt.Sprintf(" \033[90m(%s)\033[0m", strings.Join(parts, ", "))
+	}
+	if task.IsWaiting() {
+		info += fmt.Sprintf(" \033[35m⏳ @%s\033[0m", task.WaitingOn)
+	}
+	return info
+}
+
+// showWaitingTasks lists blocked tasks grouped by who they are waiting on,
+// starting with the people holding up the most tasks
+func (s *Service) showWaitingTasks(tasks []TaskInfo) error {
+	groups := make(map[string][]TaskInfo)
+	for _, task := range tasks {
+		groups[task.WaitingOn] = append(groups[task.WaitingOn], task)
+	}
+
+	people := make([]string, 0, len(groups))
+	for person := range groups {
+		people = append(people, person)
+	}
+	sort.Slice(people, func(i, j int) bool {
+		if len(groups[people[i]]) != len(groups[people[j]]) {
+			return len(groups[people[i]]) > len(groups[people[j]])
+		}
+		return strings.ToLower(people[i]) < strings.ToLower(people[j])
+	})
+
+	now := time.Now()
+	for i, person := range people {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("\033[1;35m⏳ @%s\033[0m \033[90m(%d)\033[0m\n", person, len(groups[person]))
+		for _, task := range groups[person] {
+			relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+			dueStr := ""
+			if task.DueDate != nil {
+				color := "\033[90m"
+				if task.IsOverdue(now) {
+					color = "\033[1;31m"
+				}
+				dueStr = fmt.Sprintf(" %s(due %s)\033[0m", color, formatRelativeTime(task.DueDate, task.DueHasTime))
+			}
+			fmt.Printf("  ├─ %s%s \033[90m%s:L%d\033[0m\n", task.Text, dueStr, relPath, task.Line)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+	who := "people"
+	if len(people) == 1 {
+		who = "person"
+	}
+	fmt.Printf("\033[1mWaiting on %d %s for %d task%s\033[0m\n", len(people), who, len(tasks), pluralize(len(tasks)))
+	return nil
+}
@@ -2,8 +2,9 @@ package notes
 
 import (
 	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
-	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,24 +14,45 @@ import (
 	"time"
 
 	"notes/internal/config"
+	"notes/internal/frontmatter"
 	"notes/internal/preview"
 	"notes/internal/templates"
 )
 
-var directories = []string{"daily", "projects", "meetings", "design", "learning", "todos", "archive"}
-
 type TaskInfo struct {
+	ID          string
+	Status      TaskStatus
 	Text        string
+	Recurrence  string
 	Line        int
 	Indent      int
 	DueDate     *time.Time
+	DueHasTime  bool
+	StartDate   *time.Time
+	Scheduled   *time.Time
+	WaitingOn   string
+	Assignees   []string
+	After       []string
+	Blocks      []string
+	BlockedBy   []string
 	Tags        []string
+	NoteTags    []string
 	FilePath    string
+	Priority    Priority
 	Estimate    string
 	TimeEntries []TimeEntry
 	TotalTime   time.Duration
 	Remaining   string
 	IsActive    bool
+	
+	// Subtask hierarchy, by line number within FilePath (0 = no parent)
+	Parent         int
+	Children       []int
+	Depth          int
+	SubtasksTotal  int
+	SubtasksDone   int
+	RollupTime     time.Duration
+	RollupEstimate time.Duration
 }
 
 type TimeEntry struct {
@@ -39,6 +61,11 @@ type TimeEntry struct {
 	EndTime     time.Time
 	Duration    time.Duration
 	Description string
+	Line        int    // line number in the note, when read from one
+	Indent      string // leading whitespace of the log line
+	Bullet      string // •, * or -
+	Seconds     bool   // the log line gives times to the second
+	Zone        bool   // the log line gives UTC offsets
 }
 
 type SearchResult struct {
@@ -59,7 +86,7 @@ func NewService(cfg *config.Config) *Service {
 func (s *Service) Initialize() error {
 	fmt.Printf("Initializing notes folder structure in: %s\n", s.config.BaseDir)
 	
-	for _, dir := range directories {
+	for _, dir := range s.config.InitDirs() {
 		dirPath := filepath.Join(s.config.BaseDir, dir)
 		if err := os.MkdirAll(dirPath, 0755); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
@@ -70,6 +97,16 @@ func (s *Service) Initialize() error {
 		}
 	}
 	
+	if created, err := s.config.WriteDefault(); err != nil {
+		return err
+	} else if created {
+		fmt.Printf("✓ Created config: %s/%s\n", config.VaultDir, config.ConfigFile)
+	}
+	
+	if err := s.createTemplateFiles(); err != nil {
+		return err
+	}
+	
 	if err := s.createReadme(); err != nil {
 		return err
 	}
@@ -82,42 +119,21 @@ func (s *Service) Initialize() error {
 	return nil
 }
 
-func (s *Service) Create(noteType NoteType, title string) error {
-	if err := ValidateNoteType(noteType); err != nil {
+func (s *Service) Create(noteType NoteType, title string, opts CreateOptions) error {
+	nt, err := s.lookupNoteType(noteType)
+	if err != nil {
 		return err
 	}
 	
-	var targetDir, filename string
-	
-	switch noteType {
-	case Daily:
-		targetDir = filepath.Join(s.config.BaseDir, "daily")
-		filename = time.Now().Format("2006-01-02") + ".md"
-	case Project:
-		if title == "" {
-			return fmt.Errorf("project notes require a title")
-		}
-		targetDir = filepath.Join(s.config.BaseDir, "projects")
-		filename = kebabCase(title) + ".md"
-	case Meeting:
-		if title == "" {
-			return fmt.Errorf("meeting notes require a title")
-		}
-		targetDir = filepath.Join(s.config.BaseDir, "meetings")
-		filename = time.Now().Format("2006-01-02") + "-" + kebabCase(title) + ".md"
-	case Design:
-		if title == "" {
-			return fmt.Errorf("design docs require a title")
-		}
-		targetDir = filepath.Join(s.config.BaseDir, "design")
-		filename = kebabCase(title) + ".md"
-	case Learning:
-		if title == "" {
-			return fmt.Errorf("learning notes require a title")
-		}
-		targetDir = filepath.Join(s.config.BaseDir, "learning")
-		filename = kebabCase(title) + ".md"
+	if nt.TitleRequired && title == "" {
+		return fmt.Errorf("%s notes require a title", noteType)
 	}
+	if nt.TitleRequired && kebabCase(title) == "" {
+		return fmt.Errorf("can't make a file name from %q; use a title with letters or digits", title)
+	}
+	
+	targetDir := filepath.Join(s.config.BaseDir, nt.Dir)
+	filename := nt.FileName(kebabCase(title), time.Now())
 	
 	filePath := filepath.Join(targetDir, filename)
 	
@@ -127,12 +143,17 @@ func (s *Service) Create(noteType NoteType, title string) error {
 	
 	if _, err := os.Stat(filePath); err == nil {
 		fmt.Printf("⚠ Note already exists: %s\n", filePath)
+		if opts.NoEdit {
+			return nil
+		}
 		fmt.Printf("Opening existing file...\n")
-		return s.openEditor(filePath)
+		return s.openEditor(filePath, 0)
 	}
 	
-	templateData := templates.GetTemplateData(title)
-	content := templates.Render(noteType, templateData)
+	content, err := s.renderNote(nt, title, opts.Vars)
+	if err != nil {
+		return err
+	}
 	
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to create note: %w", err)
@@ -140,19 +161,31 @@ func (s *Service) Create(noteType NoteType, title string) error {
 	
 	fmt.Printf("✅ Created new %s note: %s\n", noteType, filePath)
 	
-	if err := s.commitNote(filePath, fmt.Sprintf("Add %s note: %s", noteType, filename)); err != nil {
-		fmt.Printf("⚠ Warning: Failed to commit note to git: %v\n", err)
+	if s.config.Git.AutoCommit {
+		if err := s.commitNote(filePath, fmt.Sprintf("Add %s note: %s", noteType, filename)); err != nil {
+			fmt.Printf("⚠ Warning: Failed to commit note to git: %v\n", err)
+		}
 	}
 	
-	return s.openEditor(filePath)
+	if opts.NoEdit {
+		return nil
+	}
+	return s.openEditor(filePath, 0)
 }
 
-func (s *Service) List() error {
-	fmt.Printf("Existing notes:\n\n")
+func (s *Service) List(opts ListOptions) error {
+	var conditions []frontmatter.Condition
+	for _, expr := range opts.Where {
+		condition, err := frontmatter.ParseCondition(expr)
+		if err != nil {
+			return err
+		}
+		conditions = append(conditions, condition)
+	}
 	
-	notesDirs := []string{"daily", "projects", "meetings", "design", "learning"}
+	fmt.Printf("Existing notes:\n\n")
 	
-	for _, dir := range notesDirs {
+	for _, dir := range s.config.NoteDirs() {
 		dirPath := filepath.Join(s.config.BaseDir, dir)
 		
 		entries, err := os.ReadDir(dirPath)
@@ -164,11 +197,43 @@ func (s *Service) List() error {
 			continue
 		}
 		
-		fmt.Printf("📁 %s/\n", dir)
+		var lines []string
 		for _, entry := range entries {
-			if !entry.IsDir() && (strings.HasSuffix(entry.Name(), ".md") || strings.HasSuffix(entry.Name(), ".txt")) {
-				fmt.Printf("  %s\n", entry.Name())
+			if entry.IsDir() || (!strings.HasSuffix(entry.Name(), ".md") && !strings.HasSuffix(entry.Name(), ".txt")) {
+				continue
+			}
+			
+			content, err := os.ReadFile(filepath.Join(dirPath, entry.Name()))
+			if err != nil {
+				continue
+			}
+			fm, _, _ := frontmatter.Parse(content)
+			
+			matches := true
+			for _, condition := range conditions {
+				if !condition.Match(fm) {
+					matches = false
+					break
+				}
+			}
+			if !matches {
+				continue
+			}
+			
+			line := "  " + entry.Name()
+			if status := fm.String("status"); status != "" {
+				line += fmt.Sprintf(" \033[90m[%s]\033[0m", status)
 			}
+			lines = append(lines, line)
+		}
+		
+		if len(lines) == 0 {
+			continue
+		}
+		
+		fmt.Printf("📁 %s/\n", dir)
+		for _, line := range lines {
+			fmt.Println(line)
 		}
 		fmt.Println()
 	}
@@ -178,7 +243,7 @@ func (s *Service) List() error {
 
 func (s *Service) ShowTasks(filters TaskFilters) error {
 	// Apply smart defaults if no explicit flags
-	if !filters.All && !filters.Focus && !filters.Overdue && !filters.Today && len(filters.Tags) == 0 && filters.Priority == "" && filters.FilePattern == "" && !filters.Summary && !filters.Full {
+	if !filters.All && !filters.Focus && !filters.Overdue && !filters.Today && len(filters.Tags) == 0 && filters.Priority == "" && filters.FilePattern == "" && filters.Status == "" && !filters.Waiting && !filters.Ready && !filters.Mine && filters.Assignee == "" && !filters.Summary && !filters.Full {
 		// Check current context
 		context := s.detectCurrentContext()
 		if context != "" {
@@ -189,39 +254,30 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 			filters.Focus = true
 			fmt.Printf("\033[1;36m📋 Focus: Overdue & Today's Tasks\033[0m\n")
 		}
+	} else if filters.Waiting {
+		fmt.Printf("\033[1;36m⏳ Waiting On Others\033[0m\n")
+	} else if filters.Ready {
+		fmt.Printf("\033[1;36m🟢 Ready To Work On\033[0m\n")
 	} else if filters.Summary {
 		fmt.Printf("\033[1;36m📊 Task Overview\033[0m\n")
 	} else if filters.Focus {
 		fmt.Printf("\033[1;36m📋 Focus: Overdue & Today's Tasks\033[0m\n")
 	} else {
-		fmt.Printf("\033[1;36m📋 All Incomplete Tasks\033[0m\n")
+		fmt.Printf("\033[1;36m📋 %s\033[0m\n", statusHeading(filters.Status))
 	}
 	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n\n")
 	
 	allTasks := []TaskInfo{}
-	searchDirs := []string{"daily", "projects", "meetings", "design", "learning", "todos"}
+	s.walkNotes(func(path string) {
+		allTasks = append(allTasks, s.extractTasks(path)...)
+	})
 	
-	for _, dir := range searchDirs {
-		dirPath := filepath.Join(s.config.BaseDir, dir)
-		
-		err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
-			}
-			
-			if d.IsDir() || (!strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, ".txt")) {
-				return nil
-			}
-			
-			tasks := s.extractTasks(path)
-			allTasks = append(allTasks, tasks...)
-			
-			return nil
-		})
-		
-		if err != nil {
-			continue
+	// Dependencies can point across notes, so they are resolved over every task
+	if err := resolveDependencies(allTasks); err != nil {
+		if filters.Ready {
+			return err
 		}
+		fmt.Printf("\033[1;33m⚠ %v\033[0m\n\n", err)
 	}
 	
 	// Apply focus filter if needed
@@ -232,8 +288,14 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 	
 	filteredTasks := s.filterTasks(allTasks, filters)
 	
+	// Keep the parents of matching subtasks for context
+	contextTasks := map[string]bool{}
+	if filters.WithParents {
+		filteredTasks, contextTasks = withParents(filteredTasks, allTasks)
+	}
+	
 	if len(filteredTasks) == 0 {
-		if len(allTasks) == 0 {
+		if filters.Status == "" && countOpen(allTasks) == 0 {
 			fmt.Printf("\033[1;32m✅ No incomplete tasks found!\033[0m\n")
 			fmt.Printf("\033[90mYou're all caught up! 🎉\033[0m\n")
 		} else {
@@ -243,12 +305,17 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 		return nil
 	}
 	
+	if filters.Waiting {
+		return s.showWaitingTasks(filteredTasks)
+	}
+	
 	// Handle summary mode
 	if filters.Summary {
 		return s.showTaskSummary(filteredTasks)
 	}
 	
 	s.sortTasks(filteredTasks, filters.SortBy)
+	filteredTasks, depths := nestSubtasks(filteredTasks)
 	
 	// Show information scent (category overview) for full view
 	if !filters.Summary {
@@ -259,7 +326,7 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 	overdueTasks := 0
 	todayTasks := 0
 	
-	for _, task := range filteredTasks {
+	for i, task := range filteredTasks {
 		relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
 		
 		if relPath != currentFile {
@@ -270,74 +337,87 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 			currentFile = relPath
 		}
 		
-		priority := s.detectPriority(task.Text)
+		priority := task.Priority.Emoji()
 		priorityColor := s.getPriorityColor(priority)
-		indentStr := strings.Repeat("  ", task.Indent/2)
+		indentStr := strings.Repeat("  ", depths[i])
+		isContext := contextTasks[taskKey(task.FilePath, task.Line)]
 		
-		taskDisplay := task.Text
 		dueDateStr := ""
 		
-		if task.DueDate != nil {
+		if task.DueDate != nil && task.Status.IsOpen() {
 			now := time.Now()
-			todayStr := now.Format("2006-01-02")
-			dueDateStr := task.DueDate.Format("2006-01-02")
-			relativeTime := formatRelativeTime(task.DueDate)
+			relativeTime := formatRelativeTime(task.DueDate, task.DueHasTime)
 			
-			if dueDateStr < todayStr {
+			if task.IsOverdue(now) {
 				dueDateStr = fmt.Sprintf(" \033[1;31m(%s)\033[0m", relativeTime)
 				overdueTasks++
-			} else if dueDateStr == todayStr {
+			} else if task.IsDueToday(now) {
 				dueDateStr = fmt.Sprintf(" \033[1;33m(due %s)\033[0m", relativeTime)
 				todayTasks++
 			} else {
 				dueDateStr = fmt.Sprintf(" \033[90m(due %s)\033[0m", relativeTime)
 			}
 		}
+		if task.Recurrence != "" {
+			dueDateStr += fmt.Sprintf(" \033[35m↻ %s\033[0m", task.Recurrence)
+		}
+		dueDateStr += scheduleInfo(task, time.Now())
+		dueDateStr += dependencyInfo(task)
 		
+		// Shorten the plain text and tags before adding colour, so the cut
+		// never lands inside a character or an escape code
+		taskDisplay := task.Text
 		if len(task.Tags) > 0 {
-			tagStr := strings.Join(task.Tags, " ")
-			taskDisplay = fmt.Sprintf("%s \033[36m%s\033[0m", taskDisplay, tagStr)
+			taskDisplay += " " + strings.Join(task.Tags, " ")
 		}
-		
-		if len(taskDisplay) > 60 {
-			taskDisplay = taskDisplay[:57] + "..."
+		taskDisplay = truncateText(taskDisplay, 60)
+		if tagStr := strings.TrimPrefix(taskDisplay, task.Text+" "); len(task.Tags) > 0 && tagStr != taskDisplay {
+			taskDisplay = fmt.Sprintf("%s \033[36m%s\033[0m", task.Text, tagStr)
+		}
+		if task.Status != StatusTodo {
+			taskDisplay = fmt.Sprintf("\033[90m%s\033[0m %s", task.Status.Mark(), taskDisplay)
+		}
+		if isContext {
+			taskDisplay = "\033[90m" + taskDisplay + "\033[0m"
 		}
 		
 		// Use tree characters for better visual hierarchy
 		treeChar := "├─"
-		if task.Indent > 0 {
+		if depths[i] > 0 {
 			treeChar = "└─"
 		}
 		
+		// Worked time and estimates include subtasks for parent tasks
+		worked, estimateDur := effortFor(task)
+		
 		// Add effort estimate and time tracking info
 		estimate := task.Estimate
+		if estimateDur > 0 {
+			estimate = formatDuration(estimateDur)
+		}
 		if estimate == "" {
 			estimate = estimateTaskEffort(task.Text)
 		}
 		
 		// Show time tracking information
 		timeInfo := ""
-		if task.TotalTime > 0 {
-			totalStr := formatDuration(task.TotalTime)
-			if task.Remaining != "" {
+		if worked > 0 {
+			totalStr := formatDuration(worked)
+			if task.Remaining != "" && len(task.Children) == 0 {
 				timeInfo = fmt.Sprintf(" \033[33m[%s worked, %s left]\033[0m", totalStr, task.Remaining)
-			} else if task.Estimate != "" {
-				if estimateDur, err := parseDuration(task.Estimate); err == nil {
-					if task.TotalTime >= estimateDur {
-						timeInfo = fmt.Sprintf(" \033[32m[%s completed]\033[0m", totalStr)
-					} else {
-						timeInfo = fmt.Sprintf(" \033[33m[%s/%s]\033[0m", totalStr, formatDuration(estimateDur))
-					}
+			} else if estimateDur > 0 {
+				if worked >= estimateDur {
+					timeInfo = fmt.Sprintf(" \033[32m[%s completed]\033[0m", totalStr)
 				} else {
-					timeInfo = fmt.Sprintf(" \033[33m[%s worked]\033[0m", totalStr)
+					timeInfo = fmt.Sprintf(" \033[33m[%s/%s]\033[0m", totalStr, formatDuration(estimateDur))
 				}
 			} else {
 				timeInfo = fmt.Sprintf(" \033[33m[%s worked]\033[0m", totalStr)
 			}
 		}
 		
-		fmt.Printf("  %s%s %s%s\033[0m %s%s%s \033[90m~%s (L%d)\033[0m\n", 
-			indentStr, treeChar, priorityColor, priority, taskDisplay, dueDateStr, timeInfo, estimate, task.Line)
+		fmt.Printf("  %s%s %s%s\033[0m %s%s%s%s \033[90m~%s (L%d)\033[0m\n", 
+			indentStr, treeChar, priorityColor, priority, taskDisplay, subtaskProgress(task), dueDateStr, timeInfo, estimate, task.Line)
 	}
 	
 	fmt.Println()
@@ -355,51 +435,99 @@ func (s *Service) ShowTasks(filters TaskFilters) error {
 	return nil
 }
 
-func kebabCase(s string) string {
-	reg := regexp.MustCompile(`[^a-zA-Z0-9]+`)
-	s = reg.ReplaceAllString(s, "-")
-	s = strings.ToLower(s)
-	s = strings.Trim(s, "-")
-	return s
-}
-
-func (s *Service) openEditor(filePath string) error {
-	editors := []string{"code", os.Getenv("EDITOR"), "nano", "vim"}
+// renderNote builds the initial content for a new note, preferring the
+// vault's template file and falling back to the embedded template.
+func (s *Service) renderNote(nt config.NoteTypeConfig, title string, vars map[string]string) (string, error) {
+	noteType := NoteType(nt.Name)
+	source := templates.Source(noteType)
+	name := "built-in " + nt.Name
 	
-	for _, editor := range editors {
-		if editor == "" {
-			continue
-		}
-		
-		if editor == "code" {
-			fmt.Printf("📝 Open in editor: %s\n", filePath)
-			return nil
+	if nt.Template != "" {
+		templatePath := filepath.Join(s.config.BaseDir, s.config.Templates.Dir, nt.Template)
+		if content, err := os.ReadFile(templatePath); err == nil {
+			source = string(content)
+			name = filepath.Join(s.config.Templates.Dir, nt.Template)
 		}
 	}
 	
-	fmt.Printf("📄 File created at: %s\n", filePath)
-	return nil
+	declared, _, err := templates.SplitVariables(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid variables in template %s: %w", name, err)
+	}
+	
+	data := templates.GetTemplateData(title)
+	data.Type = nt.Name
+	data.User = s.userName()
+	data.PrevDaily = s.previousDailyNote(data.Now)
+	data.Vars, err = resolveVariables(declared, vars)
+	if err != nil {
+		return "", err
+	}
+	
+	return templates.Render(name, source, data)
 }
 
-func (s *Service) detectPriority(taskText string) string {
-	taskLower := strings.ToLower(taskText)
+func kebabCase(s string) string {
+	return templates.Slug(s)
+}
+
+// openEditor opens a file in the user's editor attached to this terminal,
+// jumping to line when it is greater than zero.
+func (s *Service) openEditor(filePath string, line int) error {
+	editor := s.editorCommand()
+	if len(editor) == 0 {
+		fmt.Printf("📄 File at: %s\n", filePath)
+		fmt.Printf("\033[90mSet $EDITOR or 'notes config set editor <command>' to open it automatically\033[0m\n")
+		return nil
+	}
 	
-	highPriorityKeywords := []string{"urgent", "asap", "critical", "important", "!!!"}
-	mediumPriorityKeywords := []string{"!!", "soon", "priority"}
+	args := append(editor[1:], editorArgs(editor[0], filePath, line)...)
+	cmd := exec.Command(editor[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 	
-	for _, keyword := range highPriorityKeywords {
-		if strings.Contains(taskLower, keyword) {
-			return "🔴"
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor %s: %w", editor[0], err)
+	}
+	return nil
+}
+
+// editorCommand picks the editor: the editor setting, then $VISUAL, then
+// $EDITOR, then the first of nano, vim or vi that is installed.
+func (s *Service) editorCommand() []string {
+	for _, candidate := range []string{s.config.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			return fields
 		}
 	}
 	
-	for _, keyword := range mediumPriorityKeywords {
-		if strings.Contains(taskLower, keyword) {
-			return "🟡"
+	for _, fallback := range []string{"nano", "vim", "vi"} {
+		if _, err := exec.LookPath(fallback); err == nil {
+			return []string{fallback}
 		}
 	}
 	
-	return "⚪"
+	return nil
+}
+
+// editorArgs returns the arguments that open filePath at line for a given
+// editor, using each editor's own goto syntax.
+func editorArgs(editor, filePath string, line int) []string {
+	if line <= 0 {
+		return []string{filePath}
+	}
+	
+	switch filepath.Base(editor) {
+	case "code", "code-insiders", "codium", "cursor":
+		return []string{"--goto", fmt.Sprintf("%s:%d", filePath, line)}
+	case "subl", "zed", "hx", "helix":
+		return []string{fmt.Sprintf("%s:%d", filePath, line)}
+	case "vim", "nvim", "vi", "nano", "emacs", "emacsclient", "micro", "kak", "mg", "joe":
+		return []string{fmt.Sprintf("+%d", line), filePath}
+	default:
+		return []string{filePath}
+	}
 }
 
 func (s *Service) getPriorityColor(priority string) string {
@@ -413,6 +541,30 @@ func (s *Service) getPriorityColor(priority string) string {
 	}
 }
 
+// statusHeading titles the task list for a --status filter
+func statusHeading(status string) string {
+	switch status {
+	case "", "open":
+		return "All Incomplete Tasks"
+	case "all":
+		return "All Tasks"
+	case "todo":
+		return "Tasks Not Started"
+	default:
+		return strings.Title(strings.ReplaceAll(status, "-", " ")) + " Tasks"
+	}
+}
+
+func countOpen(tasks []TaskInfo) int {
+	count := 0
+	for _, task := range tasks {
+		if task.Status.IsOpen() {
+			count++
+		}
+	}
+	return count
+}
+
 func pluralize(count int) string {
 	if count == 1 {
 		return ""
@@ -476,7 +628,12 @@ Thumbs.db
 }
 
 func (s *Service) commitNote(filePath, message string) error {
-	cmd := exec.Command("git", "add", filePath)
+	return s.commitFiles([]string{filePath}, message)
+}
+
+// commitFiles stages the given files and commits them in one commit
+func (s *Service) commitFiles(paths []string, message string) error {
+	cmd := exec.Command("git", append([]string{"add", "--"}, paths...)...)
 	cmd.Dir = s.config.BaseDir
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to add file to git: %w", err)
@@ -521,22 +678,26 @@ func (s *Service) SaveChanges(message string) error {
 }
 
 func (s *Service) extractTasks(filePath string) []TaskInfo {
-	file, err := os.Open(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil
 	}
-	defer file.Close()
 	
-	taskPattern := regexp.MustCompile(`^(\s*)-\s*\[\s*\]\s*(.*)$`)
-	dueDatePattern := regexp.MustCompile(`due:(\d{4}-\d{2}-\d{2})`)
-	estimatePattern := regexp.MustCompile(`est:([^\\s]+)`)
-	tagPattern := regexp.MustCompile(`#(\w+)`)
+	// Frontmatter lines are never tasks, but its tags apply to every task.
+	// Checkboxes in code blocks are examples, not tasks.
+	fm, _, _ := frontmatter.Parse(content)
+	noteText := noteTextLines(strings.Split(string(content), "\n"))
+	noteTags := fm.Tags()
+	attendees := s.meetingAttendees(filePath, fm)
+	
+	dueDatePattern := regexp.MustCompile(`due:(\S+)`)
+	now := time.Now()
+	estimatePattern := regexp.MustCompile(`est:(\S+)`)
 	timeLogPattern := regexp.MustCompile(`^\s*Time log:\s*$`)
-	timeEntryPattern := regexp.MustCompile(`^\s*•`)
 	remainingPattern := regexp.MustCompile(`^\s*Remaining:\s*(.+)$`)
 	totalPattern := regexp.MustCompile(`^\s*Total:\s*(.+)$`)
 	
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
 	lineNum := 0
 	tasks := []TaskInfo{}
 	var currentTask *TaskInfo
@@ -546,31 +707,89 @@ func (s *Service) extractTasks(filePath string) []TaskInfo {
 		lineNum++
 		line := scanner.Text()
 		
+		if !noteText[lineNum-1] {
+			continue
+		}
+		
 		// Check for task line
-		if match := taskPattern.FindStringSubmatch(line); match != nil {
+		if match := taskLinePattern.FindStringSubmatch(line); match != nil {
 			// Save previous task if exists
 			if currentTask != nil {
 				tasks = append(tasks, *currentTask)
 			}
 			
-			taskText := strings.TrimSpace(match[2])
+			taskText := strings.TrimSpace(match[3])
 			indent := len(match[1])
 			
 			currentTask = &TaskInfo{
+				Status:      statusMarks[match[2]],
 				Text:        taskText,
 				Line:        lineNum,
 				Indent:      indent,
 				FilePath:    filePath,
+				NoteTags:    noteTags,
 				TimeEntries: []TimeEntry{},
 			}
 			inTimeLog = false
 			
-			// Parse due date
+			// Parse stable block ID (^t-3f9a01c2)
+			if idMatch := taskIDPattern.FindStringSubmatch(taskText); idMatch != nil {
+				currentTask.ID = idMatch[1]
+				currentTask.Text = taskIDPattern.ReplaceAllString(currentTask.Text, "")
+			}
+			
+			// Parse dependencies: id:, after:<id> and blocks:<id>
+			if idMatch := idTokenPattern.FindStringSubmatch(taskText); idMatch != nil {
+				if currentTask.ID == "" {
+					currentTask.ID = strings.TrimPrefix(idMatch[2], "^")
+				}
+				currentTask.Text = idTokenPattern.ReplaceAllString(currentTask.Text, "")
+			}
+			for _, afterMatch := range afterPattern.FindAllStringSubmatch(taskText, -1) {
+				currentTask.After = append(currentTask.After, parseTaskRefs(afterMatch[2])...)
+			}
+			for _, blocksMatch := range blocksPattern.FindAllStringSubmatch(taskText, -1) {
+				currentTask.Blocks = append(currentTask.Blocks, parseTaskRefs(blocksMatch[2])...)
+			}
+			currentTask.Text = afterPattern.ReplaceAllString(currentTask.Text, "")
+			currentTask.Text = blocksPattern.ReplaceAllString(currentTask.Text, "")
+			
+			// Parse due date, which may be relative (due:fri) or have a time
 			if dueDateMatch := dueDatePattern.FindStringSubmatch(taskText); dueDateMatch != nil {
-				if dueDate, err := time.Parse("2006-01-02", dueDateMatch[1]); err == nil {
+				if dueDate, hasTime, err := parseDate(dueDateMatch[1], now); err == nil {
 					currentTask.DueDate = &dueDate
+					currentTask.DueHasTime = hasTime
+					currentTask.Text = dueDatePattern.ReplaceAllString(currentTask.Text, "")
+				}
+			}
+			
+			// Parse start and scheduled dates, which hide the task until then
+			if startMatch := startDatePattern.FindStringSubmatch(taskText); startMatch != nil {
+				if startDate, _, err := parseDate(startMatch[1], now); err == nil {
+					currentTask.StartDate = &startDate
+					currentTask.Text = startDatePattern.ReplaceAllString(currentTask.Text, "")
+				}
+			}
+			if scheduledMatch := scheduledPattern.FindStringSubmatch(taskText); scheduledMatch != nil {
+				if scheduled, _, err := parseDate(scheduledMatch[1], now); err == nil {
+					currentTask.Scheduled = &scheduled
+					currentTask.Text = scheduledPattern.ReplaceAllString(currentTask.Text, "")
 				}
-				currentTask.Text = dueDatePattern.ReplaceAllString(currentTask.Text, "")
+			}
+			
+			// Parse who the task is waiting on (waiting:@alice)
+			if waitingMatch := waitingPattern.FindStringSubmatch(taskText); waitingMatch != nil {
+				currentTask.WaitingOn = waitingMatch[1]
+				currentTask.Text = waitingPattern.ReplaceAllString(currentTask.Text, "")
+			}
+			
+			// Parse priority (!1, p:high or a leading (A))
+			currentTask.Priority, currentTask.Text = s.taskPriority(currentTask.Text)
+			
+			// Parse recurrence rule
+			if recurrenceMatch := recurrencePattern.FindStringSubmatch(taskText); recurrenceMatch != nil {
+				currentTask.Recurrence = recurrenceMatch[1]
+				currentTask.Text = recurrencePattern.ReplaceAllString(currentTask.Text, "")
 			}
 			
 			// Parse estimate
@@ -580,9 +799,15 @@ func (s *Service) extractTasks(filePath string) []TaskInfo {
 			}
 			
 			// Parse tags
-			tagMatches := tagPattern.FindAllStringSubmatch(taskText, -1)
-			for _, tagMatch := range tagMatches {
-				currentTask.Tags = append(currentTask.Tags, "#"+tagMatch[1])
+			currentTask.Tags = extractTags(taskText)
+			
+			// Parse assignees (@bob); meeting action items like "Bob: ..."
+			// belong to that attendee
+			currentTask.Assignees = parseMentions(currentTask.Text)
+			if len(currentTask.Assignees) == 0 && attendees != nil {
+				if owner := actionItemOwner(currentTask.Text, attendees); owner != "" {
+					currentTask.Assignees = []string{owner}
+				}
 			}
 			
 			currentTask.Text = strings.TrimSpace(currentTask.Text)
@@ -601,8 +826,9 @@ func (s *Service) extractTasks(filePath string) []TaskInfo {
 		}
 		
 		// Parse time entries
-		if inTimeLog && timeEntryPattern.MatchString(line) {
+		if inTimeLog && timeEntryLinePattern.MatchString(line) {
 			if entry, err := parseTimeEntry(line); err == nil {
+				entry.Line = lineNum
 				currentTask.TimeEntries = append(currentTask.TimeEntries, *entry)
 				currentTask.TotalTime += entry.Duration
 			}
@@ -611,14 +837,17 @@ func (s *Service) extractTasks(filePath string) []TaskInfo {
 		
 		// Parse remaining time
 		if remainingMatch := remainingPattern.FindStringSubmatch(line); remainingMatch != nil {
-			currentTask.Remaining = strings.TrimSpace(remainingMatch[1])
+			// "Remaining: 0m (over estimate by 1h)" keeps just the time
+			if fields := strings.Fields(remainingMatch[1]); len(fields) > 0 {
+				currentTask.Remaining = fields[0]
+			}
 			inTimeLog = false
 			continue
 		}
 		
 		// Parse total time
 		if totalMatch := totalPattern.FindStringSubmatch(line); totalMatch != nil {
-			if duration, err := parseDuration(strings.TrimSpace(totalMatch[1])); err == nil {
+			if duration, err := parseDuration(strings.SplitN(strings.TrimSpace(totalMatch[1]), " ", 2)[0]); err == nil {
 				currentTask.TotalTime = duration
 			}
 			inTimeLog = false
@@ -636,10 +865,11 @@ func (s *Service) extractTasks(filePath string) []TaskInfo {
 		tasks = append(tasks, *currentTask)
 	}
 	
+	linkSubtasks(tasks)
 	return tasks
 }
 
-func (s *Service) Search(query string, searchTags []string) error {
+func (s *Service) Search(query string, searchTags []string, opts SearchOptions) error {
 	fmt.Printf("\033[1;36m🔍 Search Results for: \"%s\"\033[0m", query)
 	if len(searchTags) > 0 {
 		fmt.Printf(" \033[36m%s\033[0m", strings.Join(searchTags, " "))
@@ -648,32 +878,11 @@ func (s *Service) Search(query string, searchTags []string) error {
 	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n\n")
 	
 	results := []SearchResult{}
-	searchDirs := []string{"daily", "projects", "meetings", "design", "learning", "todos"}
-	
 	queryLower := strings.ToLower(query)
 	
-	for _, dir := range searchDirs {
-		dirPath := filepath.Join(s.config.BaseDir, dir)
-		
-		err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
-			}
-			
-			if d.IsDir() || (!strings.HasSuffix(path, ".md") && !strings.HasSuffix(path, ".txt")) {
-				return nil
-			}
-			
-			fileResults := s.searchInFile(path, queryLower, searchTags)
-			results = append(results, fileResults...)
-			
-			return nil
-		})
-		
-		if err != nil {
-			continue
-		}
-	}
+	s.walkNotes(func(path string) {
+		results = append(results, s.searchInFile(path, queryLower, searchTags)...)
+	})
 	
 	if len(results) == 0 {
 		fmt.Printf("\033[90mNo results found.\033[0m\n")
@@ -692,10 +901,7 @@ func (s *Service) Search(query string, searchTags []string) error {
 			currentFile = relPath
 		}
 		
-		content := result.Content
-		if len(content) > 100 {
-			content = content[:97] + "..."
-		}
+		content := truncateText(result.Content, 100)
 		
 		tagStr := ""
 		if len(result.Tags) > 0 {
@@ -708,49 +914,69 @@ func (s *Service) Search(query string, searchTags []string) error {
 	fmt.Printf("\n\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
 	fmt.Printf("\033[1mFound %d result%s\033[0m\n", len(results), pluralize(len(results)))
 	
+	if opts.Open {
+		top := results[0]
+		return s.openEditor(top.FilePath, top.Line)
+	}
+	
 	return nil
 }
 
 func (s *Service) searchInFile(filePath, query string, searchTags []string) []SearchResult {
-	file, err := os.Open(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil
 	}
-	defer file.Close()
 	
-	tagPattern := regexp.MustCompile(`#(\w+)`)
+	fm, _, _ := frontmatter.Parse(content)
+	skipLines := 0
+	if fm != nil {
+		skipLines = fm.Lines
+	}
+	noteTags := fm.Tags()
+	
 	results := []SearchResult{}
 	
-	scanner := bufio.NewScanner(file)
+	// A tag search with no text matches the note once, through its frontmatter
+	if query == "" && len(searchTags) > 0 && matchesAnyTag(noteTags, searchTags) {
+		title := fm.Title()
+		if title == "" {
+			title = "tags: " + strings.Join(noteTags, " ")
+		}
+		results = append(results, SearchResult{
+			FilePath: filePath,
+			Line:     1,
+			Content:  title,
+			Tags:     noteTags,
+		})
+		return results
+	}
+	
+	scanner := bufio.NewScanner(bytes.NewReader(content))
 	lineNum := 0
+	inFence := false
 	
 	for scanner.Scan() {
 		lineNum++
 		line := scanner.Text()
 		lineLower := strings.ToLower(line)
 		
+		if lineNum <= skipLines {
+			continue
+		}
+		
 		lineMatches := query == "" || strings.Contains(lineLower, query)
 		
+		// Code blocks are searched as text but never hold tags
+		if codeFencePattern.MatchString(line) {
+			inFence = !inFence
+		}
 		lineTags := []string{}
-		tagMatches := tagPattern.FindAllStringSubmatch(line, -1)
-		for _, tagMatch := range tagMatches {
-			lineTags = append(lineTags, "#"+tagMatch[1])
+		if !inFence {
+			lineTags = extractTags(line)
 		}
 		
-		tagSearchMatches := len(searchTags) == 0
-		if len(searchTags) > 0 {
-			for _, searchTag := range searchTags {
-				for _, lineTag := range lineTags {
-					if strings.EqualFold(lineTag, searchTag) {
-						tagSearchMatches = true
-						break
-					}
-				}
-				if tagSearchMatches {
-					break
-				}
-			}
-		}
+		tagSearchMatches := len(searchTags) == 0 || matchesAnyTag(lineTags, searchTags) || matchesAnyTag(noteTags, searchTags)
 		
 		if lineMatches && tagSearchMatches {
 			results = append(results, SearchResult{
@@ -766,15 +992,19 @@ func (s *Service) searchInFile(filePath, query string, searchTags []string) []Se
 }
 
 func (s *Service) filterTasks(tasks []TaskInfo, filters TaskFilters) []TaskInfo {
-	if len(filters.Tags) == 0 && filters.Priority == "" && !filters.Overdue && !filters.Today && filters.FilePattern == "" {
-		return tasks
-	}
-	
 	filtered := []TaskInfo{}
 	now := time.Now()
+	var me []string
+	if filters.Mine {
+		me = s.myNames()
+	}
+	people := rosterOf(tasks, me...)
 	
 	for _, task := range tasks {
-		if !s.matchesFilters(task, filters, now) {
+		if !s.matchesFilters(task, filters, now, people) {
+			continue
+		}
+		if filters.Mine && !task.isAssignedToAny(me, people) {
 			continue
 		}
 		filtered = append(filtered, task)
@@ -783,52 +1013,55 @@ func (s *Service) filterTasks(tasks []TaskInfo, filters TaskFilters) []TaskInfo
 	return filtered
 }
 
-func (s *Service) matchesFilters(task TaskInfo, filters TaskFilters, now time.Time) bool {
+func (s *Service) matchesFilters(task TaskInfo, filters TaskFilters, now time.Time, people roster) bool {
+	if !matchesStatus(task.Status, filters.Status) {
+		return false
+	}
+	
+	// Tasks that can't be started yet stay out of the way until they can
+	if !filters.Upcoming && task.Status.IsOpen() && task.IsUpcoming(now) {
+		return false
+	}
+	
+	if filters.Waiting && !task.IsWaiting() {
+		return false
+	}
+	
+	if filters.Ready && !task.IsReady(now) {
+		return false
+	}
+	
+	if filters.Assignee != "" && !task.IsAssignedTo(filters.Assignee, people) {
+		return false
+	}
+	
+	// Filtering by a tag also matches the tags nested below it
 	if len(filters.Tags) > 0 {
-		hasMatchingTag := false
-		for _, filterTag := range filters.Tags {
-			for _, taskTag := range task.Tags {
-				if strings.EqualFold(taskTag, filterTag) {
-					hasMatchingTag = true
-					break
-				}
-			}
-			if hasMatchingTag {
-				break
-			}
-		}
-		if !hasMatchingTag {
+		taskTags := append(append([]string{}, task.Tags...), task.NoteTags...)
+		if !matchesAnyTag(taskTags, filters.Tags) {
 			return false
 		}
 	}
 	
 	if filters.Priority != "" {
-		taskPriority := s.detectPriority(task.Text)
-		priorityLevel := s.getPriorityLevel(taskPriority)
-		if !s.matchesPriorityFilter(priorityLevel, filters.Priority) {
+		if priority, _ := parsePriority(filters.Priority); task.Priority != priority {
 			return false
 		}
 	}
 	
 	// Handle focus mode (both overdue and today) with OR logic
+	isToday := (task.DueDate != nil && daysUntil(*task.DueDate, now) == 0) || task.IsScheduledNow(now)
 	if filters.Overdue && filters.Today {
-		if task.DueDate == nil {
-			return false
-		}
-		taskDateStr := task.DueDate.Format("2006-01-02")
-		todayStr := now.Format("2006-01-02")
-		isOverdue := taskDateStr < todayStr
-		isToday := taskDateStr == todayStr
-		if !isOverdue && !isToday {
+		if !task.IsOverdue(now) && !isToday {
 			return false
 		}
 	} else {
 		// Handle individual filters with AND logic
-		if filters.Overdue && (task.DueDate == nil || task.DueDate.Format("2006-01-02") >= now.Format("2006-01-02")) {
+		if filters.Overdue && !task.IsOverdue(now) {
 			return false
 		}
 		
-		if filters.Today && (task.DueDate == nil || task.DueDate.Format("2006-01-02") != now.Format("2006-01-02")) {
+		if filters.Today && !isToday {
 			return false
 		}
 	}
@@ -840,33 +1073,12 @@ func (s *Service) matchesFilters(task TaskInfo, filters TaskFilters, now time.Ti
 	return true
 }
 
-func (s *Service) getPriorityLevel(priorityEmoji string) string {
-	switch priorityEmoji {
-	case "🔴":
-		return "high"
-	case "🟡":
-		return "medium"
-	default:
-		return "low"
-	}
-}
-
-func (s *Service) matchesPriorityFilter(taskPriority, filterPriority string) bool {
-	return strings.EqualFold(taskPriority, filterPriority)
-}
-
 func (s *Service) sortTasks(tasks []TaskInfo, sortBy string) {
 	switch strings.ToLower(sortBy) {
 	case "priority":
 		sort.Slice(tasks, func(i, j int) bool {
-			iPriority := s.detectPriority(tasks[i].Text)
-			jPriority := s.detectPriority(tasks[j].Text)
-			iPriorityLevel := s.getPriorityLevel(iPriority)
-			jPriorityLevel := s.getPriorityLevel(jPriority)
-			
-			priorityOrder := map[string]int{"high": 0, "medium": 1, "low": 2}
-			if priorityOrder[iPriorityLevel] != priorityOrder[jPriorityLevel] {
-				return priorityOrder[iPriorityLevel] < priorityOrder[jPriorityLevel]
+			if tasks[i].Priority.rank() != tasks[j].Priority.rank() {
+				return tasks[i].Priority.rank() < tasks[j].Priority.rank()
 			}
 			
 			if tasks[i].DueDate != nil && tasks[j].DueDate != nil {
@@ -935,25 +1147,22 @@ func (s *Service) showTaskSummary(tasks []TaskInfo) error {
 	if len(criticalTasks) > 0 {
 		fmt.Printf("\033[1mTop Critical Tasks:\033[0m\n")
 		for i, task := range criticalTasks {
-			priority := s.detectPriority(task.Text)
+			priority := task.Priority.Emoji()
 			priorityColor := s.getPriorityColor(priority)
 			
 			relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
 			estimate := estimateTaskEffort(task.Text)
 			
-			taskDisplay := task.Text
-			if len(taskDisplay) > 50 {
-				taskDisplay = taskDisplay[:47] + "..."
-			}
+			taskDisplay := truncateText(task.Text, 50)
 			
 			dueDateStr := ""
 			if task.DueDate != nil {
-				relativeTime := formatRelativeTime(task.DueDate)
+				relativeTime := formatRelativeTime(task.DueDate, task.DueHasTime)
 				dueDateStr = fmt.Sprintf(" \033[90m(%s)\033[0m", relativeTime)
 			}
 			
-			fmt.Printf("[%d] %s%s\033[0m %s%s \033[90m~%s • %s:L%d\033[0m\n",
-				i+1, priorityColor, priority, taskDisplay, dueDateStr, estimate, relPath, task.Line)
+			fmt.Printf("[%d] %s%s\033[0m %s%s%s \033[90m~%s • %s:L%d\033[0m\n",
+				i+1, priorityColor, priority, taskDisplay, subtaskProgress(task), dueDateStr, estimate, relPath, task.Line)
 		}
 	}
 	
@@ -987,11 +1196,15 @@ func (s *Service) HandleTimeCommand(args []string) error {
 	
 	switch command {
 	case "start":
-		if len(commandArgs) == 0 {
+		words, message, err := splitMessageFlag(commandArgs)
+		if err != nil {
+			return err
+		}
+		if len(words) == 0 {
 			return fmt.Errorf("start command requires a task description")
 		}
-		taskText := strings.Join(commandArgs, " ")
-		return s.startTimer(taskText)
+		taskText := strings.Join(words, " ")
+		return s.startTimer(taskText, message)
 	case "pause":
 		return s.pauseTimer()
 	case "resume":
@@ -1001,25 +1214,78 @@ func (s *Service) HandleTimeCommand(args []string) error {
 		}
 		return s.resumeTimer(taskText)
 	case "stop":
-		return s.stopTimer()
+		words, message, err := splitMessageFlag(commandArgs)
+		if err != nil {
+			return err
+		}
+		if len(words) > 0 {
+			return fmt.Errorf("unexpected argument: %s (describe the session with -m)", words[0])
+		}
+		return s.stopTimer(message)
+	case "log":
+		return s.logTime(commandArgs)
+	case "amend":
+		words, message, err := splitMessageFlag(commandArgs)
+		if err != nil {
+			return err
+		}
+		if message == "" || len(words) > 0 {
+			return fmt.Errorf("usage: notes time amend -m \"what you did\"")
+		}
+		return s.amendLastEntry(message)
+	case "entries":
+		return s.listTimeEntries(commandArgs)
+	case "edit":
+		return s.editTimeEntry(commandArgs)
+	case "split":
+		return s.splitTimeEntry(commandArgs)
+	case "rm":
+		return s.removeTimeEntry(commandArgs)
+	case "recalc":
+		dryRun := false
+		for _, arg := range commandArgs {
+			if arg != "--dry-run" && arg != "-n" {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			dryRun = true
+		}
+		return s.RecalcTimeSummaries(dryRun)
 	case "status":
 		return s.showTimerStatus()
 	case "report":
 		period := "today"
-		if len(commandArgs) > 0 {
-			period = commandArgs[0]
+		person := ""
+		asCSV := false
+		for i := 0; i < len(commandArgs); i++ {
+			switch arg := commandArgs[i]; {
+			case arg == "--mine":
+				person = "me"
+			case arg == "--csv":
+				asCSV = true
+			case arg == "--assignee":
+				if i+1 >= len(commandArgs) {
+					return fmt.Errorf("--assignee requires a name")
+				}
+				i++
+				person = strings.TrimPrefix(commandArgs[i], "@")
+			case strings.HasPrefix(arg, "--"):
+				return fmt.Errorf("unknown flag: %s", arg)
+			default:
+				period = arg
+			}
 		}
-		return s.showTimeReport(period)
+		return s.showTimeReport(period, person, asCSV)
 	default:
 		return fmt.Errorf("unknown time command: %s", command)
 	}
 }
 
 
-func (s *Service) startTimer(taskText string) error {
-	// First, stop any existing timer
-	if err := s.stopTimer(); err != nil {
-		// Continue even if stop fails (no active timer)
+func (s *Service) startTimer(taskText, description string) error {
+	// First, stop any existing timer. If its session can't be logged, keep
+	// the timer state rather than overwrite it.
+	if err := s.stopTimer(""); err != nil && !errors.Is(err, errNoActiveTimer) {
+		return fmt.Errorf("could not stop the running timer: %w", err)
 	}
 	
 	// Find the task in markdown files
@@ -1028,14 +1294,22 @@ func (s *Service) startTimer(taskText string) error {
 		return fmt.Errorf("could not find task: %w", err)
 	}
 	
+	// Give the task a stable ID so the time log still finds it if the
+	// file is edited while the timer runs
+	if _, err := s.ensureTaskID(task); err != nil {
+		return fmt.Errorf("failed to add task ID: %w", err)
+	}
+	
 	// Save timer state
 	state := TimerState{
-		IsActive:   true,
-		TaskText:   task.Text,
-		FilePath:   task.FilePath,
-		TaskLine:   task.Line,
-		StartTime:  time.Now(),
-		IsPaused:   false,
+		IsActive:    true,
+		TaskID:      task.ID,
+		TaskText:    task.Text,
+		FilePath:    task.FilePath,
+		TaskLine:    task.Line,
+		StartTime:   time.Now(),
+		IsPaused:    false,
+		Description: description,
 	}
 	
 	if err := s.saveTimerState(state); err != nil {
@@ -1044,7 +1318,7 @@ func (s *Service) startTimer(taskText string) error {
 	
 	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
 	fmt.Printf("⏰ Started timer for: \033[1m%s\033[0m\n", task.Text)
-	fmt.Printf("\033[90mLocation: %s:L%d\033[0m\n", relPath, task.Line)
+	fmt.Printf("\033[90mLocation: %s:L%d • ID: ^%s\033[0m\n", relPath, task.Line, task.ID)
 	
 	return nil
 }
@@ -1084,7 +1358,7 @@ func (s *Service) resumeTimer(taskText string) error {
 		if taskText == "" {
 			return fmt.Errorf("no paused timer found and no task specified")
 		}
-		return s.startTimer(taskText)
+		return s.startTimer(taskText, "")
 	}
 	
 	if !state.IsPaused {
@@ -1105,10 +1379,28 @@ func (s *Service) resumeTimer(taskText string) error {
 	return nil
 }
 
-func (s *Service) stopTimer() error {
+// errNoActiveTimer is returned by stopTimer when no timer is running
+var errNoActiveTimer = errors.New("no active timer found")
+
+// stopTimer logs the running session. Its description is the one given
+// here, else the one given at start, else what the user types when asked
+// on a terminal.
+func (s *Service) stopTimer(description string) error {
 	state, err := s.loadTimerState()
-	if err != nil || !state.IsActive {
-		return fmt.Errorf("no active timer found")
+	if errors.Is(err, os.ErrNotExist) || err == nil && !state.IsActive {
+		return errNoActiveTimer
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read timer state: %w", err)
+	}
+	
+	if description != "" {
+		state.Description = description
+	} else if state.Description == "" && isInteractive() {
+		answer, err := promptLine(fmt.Sprintf("What did you work on for %q? (Enter to skip) ", state.TaskText))
+		if err == nil {
+			state.Description = answer
+		}
 	}
 	
 	// Calculate total elapsed time
@@ -1151,21 +1443,42 @@ func (s *Service) showTimerStatus() error {
 	}
 	
 	relPath, _ := filepath.Rel(s.config.BaseDir, state.FilePath)
+	taskLine := state.TaskLine
+	if task, err := s.locateTask(state.FilePath, state.TaskID, state.TaskText); err == nil {
+		taskLine = task.Line
+	} else {
+		fmt.Printf("\033[1;33m⚠ %v\033[0m\n", err)
+	}
 	
 	fmt.Printf("%s: \033[1m%s\033[0m\n", status, state.TaskText)
 	fmt.Printf("\033[90mElapsed: %s • Location: %s:L%d\033[0m\n", 
-		formatDuration(elapsed), relPath, state.TaskLine)
+		formatDuration(elapsed), relPath, taskLine)
 	
 	return nil
 }
 
-func (s *Service) showTimeReport(period string) error {
-	report, err := s.collectTimeData(period)
+// showTimeReport prints the time logged in a period, optionally only on
+// tasks assigned to person ("me" for the current user), as a report or as
+// CSV with one row per session
+func (s *Service) showTimeReport(period, person string, asCSV bool) error {
+	var people []string
+	switch person {
+	case "":
+	case "me":
+		people = s.myNames()
+	default:
+		people = []string{person}
+	}
+	
+	report, err := s.collectTimeData(period, people)
 	if err != nil {
 		return err
 	}
+	report.Person = person
 	
-	
+	if asCSV {
+		return s.writeTimeCSV(report, os.Stdout)
+	}
 	s.formatTimeReport(report)
 	return nil
 }
@@ -1282,18 +1595,17 @@ func (s *Service) showChangedNotes(changedFiles map[string]string) {
 func (s *Service) getNoteSummary(relativeFilePath string) string {
 	fullPath := filepath.Join(s.config.BaseDir, relativeFilePath)
 	
-	file, err := os.Open(fullPath)
+	content, err := os.ReadFile(fullPath)
 	if err != nil {
 		return ""
 	}
-	defer file.Close()
 	
-	scanner := bufio.NewScanner(file)
+	fm, body, _ := frontmatter.Parse(content)
+	
+	scanner := bufio.NewScanner(bytes.NewReader(body))
 	lineCount := 0
 	taskCount := 0
-	var title string
-	
-	taskPattern := regexp.MustCompile(`^(\s*)-\s*\[\s*[\sx]\s*\]\s*(.*)$`)
+	title := truncateText(fm.Title(), 30)
 	
 	for scanner.Scan() && lineCount < 20 { // Only scan first 20 lines for performance
 		line := scanner.Text()
@@ -1303,20 +1615,14 @@ func (s *Service) getNoteSummary(relativeFilePath string) string {
 		if title == "" {
 			trimmed := strings.TrimSpace(line)
 			if strings.HasPrefix(trimmed, "# ") {
-				title = strings.TrimPrefix(trimmed, "# ")
-				if len(title) > 30 {
-					title = title[:27] + "..."
-				}
-			} else if len(trimmed) > 10 && !strings.HasPrefix(trimmed, "---") {
-				title = trimmed
-				if len(title) > 30 {
-					title = title[:27] + "..."
-				}
+				title = truncateText(strings.TrimPrefix(trimmed, "# "), 30)
+			} else if len(trimmed) > 10 {
+				title = truncateText(trimmed, 30)
 			}
 		}
 		
-		// Count tasks
-		if taskPattern.MatchString(line) {
+		// Count open tasks
+		if match := taskLinePattern.FindStringSubmatch(line); match != nil && statusMarks[match[2]].IsOpen() {
 			taskCount++
 		}
 	}
@@ -1326,7 +1632,7 @@ func (s *Service) getNoteSummary(relativeFilePath string) string {
 		summary = append(summary, fmt.Sprintf("\"%s\"", title))
 	}
 	if taskCount > 0 {
-		summary = append(summary, fmt.Sprintf("%d task%s", taskCount, pluralize(taskCount)))
+		summary = append(summary, fmt.Sprintf("%d open task%s", taskCount, pluralize(taskCount)))
 	}
 	
 	if len(summary) > 0 {
@@ -1412,7 +1718,11 @@ func (s *Service) analyzeTodoChanges(relativeFilePath, status string) TodoChange
 		tasks := s.extractTasks(fullPath)
 		for _, task := range tasks {
 			todoText := s.formatTodoForStatus(task, relativeFilePath)
-			changes.New = append(changes.New, todoText)
+			if task.Status == StatusDone {
+				changes.Completed = append(changes.Completed, todoText)
+			} else if task.Status.IsOpen() {
+				changes.New = append(changes.New, todoText)
+			}
 		}
 		return changes
 	}
@@ -1428,12 +1738,18 @@ func (s *Service) analyzeTodoChanges(relativeFilePath, status string) TodoChange
 	// Parse diff output for todo changes
 	diffLines := strings.Split(string(output), "\n")
 	
-	todoPattern := regexp.MustCompile(`^[\+\-]\s*-\s*\[\s*([\sx]?)\s*\]\s*(.*)$`)
-	
 	for _, line := range diffLines {
-		if match := todoPattern.FindStringSubmatch(line); match != nil {
-			isCompleted := strings.TrimSpace(match[1]) == "x"
-			todoText := strings.TrimSpace(match[2])
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || len(line) == 0 {
+			continue
+		}
+		if match := taskLinePattern.FindStringSubmatch(line[1:]); match != nil && (line[0] == '+' || line[0] == '-') {
+			status := statusMarks[match[2]]
+			isCompleted := status == StatusDone
+			if !isCompleted && !status.IsOpen() {
+				// Cancelled and deferred tasks are neither new nor completed
+				continue
+			}
+			todoText := strings.TrimSpace(taskIDPattern.ReplaceAllString(match[3], ""))
 			changeType := string(line[0]) // '+' or '-'
 			
 			if changeType == "+" {
@@ -1468,10 +1784,7 @@ func (s *Service) analyzeTodoChanges(relativeFilePath, status string) TodoChange
 
 // formatTodoForStatus formats a todo for status display
 func (s *Service) formatTodoForStatus(task TaskInfo, relativeFilePath string) string {
-	todoText := task.Text
-	if len(todoText) > 60 {
-		todoText = todoText[:57] + "..."
-	}
+	todoText := truncateText(task.Text, 60)
 	
 	return fmt.Sprintf("%s \033[90m(%s:L%d)\033[0m", todoText, relativeFilePath, task.Line)
 }
@@ -1493,10 +1806,11 @@ func (s *Service) removeDuplicates(items []string) []string {
 
 func (s *Service) StartPreview(port int) error {
 	if port == 0 {
-		port = 8080
+		port = s.config.Preview.Port
 	}
 	
 	server := preview.NewServer(s.config.BaseDir, port)
+	server.ToggleTask = s.ToggleTask
 	return server.Start()
 }
 
@@ -0,0 +1,109 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notes/internal/config"
+)
+
+// newTestVault writes files (vault path to content) into a fresh vault
+// with the default settings and returns a service for it. Auto-commit is
+// off, since the vault isn't a git repository.
+func newTestVault(t *testing.T, files map[string]string) *Service {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
+	cfg := config.Default()
+	cfg.BaseDir = dir
+	cfg.Git.AutoCommit = false
+	for path, content := range files {
+		writeNote(t, cfg.BaseDir, path, content)
+	}
+	return NewService(cfg)
+}
+
+func writeNote(t *testing.T, baseDir, path, content string) {
+	t.Helper()
+	fullPath := filepath.Join(baseDir, path)
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readNote returns the content of a note in the vault
+func readNote(t *testing.T, s *Service, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(s.config.BaseDir, path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// lines builds note content from lines, ending with a newline
+func lines(content ...string) string {
+	return strings.Join(content, "\n") + "\n"
+}
+
+func TestStartTimerStopsRunningTimer(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/p.md": lines("- [ ] Write docs ^t-docs", "- [ ] Fix bug ^t-bug"),
+	})
+	if err := s.HandleTimeCommand([]string{"start", "^t-docs", "-m", "Intro"}); err != nil {
+		t.Fatalf("time start: %v", err)
+	}
+	if err := s.HandleTimeCommand([]string{"start", "^t-bug", "-m", "Repro"}); err != nil {
+		t.Fatalf("time start with a timer running: %v", err)
+	}
+
+	if note := readNote(t, s, "projects/p.md"); !strings.Contains(note, ") - Intro") {
+		t.Errorf("the first session was not logged:\n%s", note)
+	}
+	state, err := s.loadTimerState()
+	if err != nil || state.TaskID != "t-bug" {
+		t.Errorf("timer state = %+v, %v, want a timer for ^t-bug", state, err)
+	}
+}
+
+func TestStartTimerKeepsUnloggedSession(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/p.md": lines("- [ ] Write docs ^t-docs", "- [ ] Fix bug ^t-bug"),
+	})
+	if err := s.HandleTimeCommand([]string{"start", "^t-docs", "-m", "Intro"}); err != nil {
+		t.Fatalf("time start: %v", err)
+	}
+	before := readNote(t, s, ".timer_state.json")
+
+	// The running timer's task is gone, so its session can't be logged
+	writeNote(t, s.config.BaseDir, "projects/p.md", lines("- [ ] Fix bug ^t-bug"))
+	if err := s.HandleTimeCommand([]string{"start", "^t-bug"}); err == nil {
+		t.Fatalf("time start: want an error when the running session can't be logged")
+	}
+	if after := readNote(t, s, ".timer_state.json"); after != before {
+		t.Errorf("timer state changed:\n%s\nwant\n%s", after, before)
+	}
+}
+
+func TestCreateNoteFileName(t *testing.T) {
+	s := newTestVault(t, nil)
+	if err := s.Create(Project, "日本語 メモ", CreateOptions{NoEdit: true}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.config.BaseDir, "projects", "日本語-メモ.md")); err != nil {
+		t.Errorf("note not created under its Unicode title: %v", err)
+	}
+
+	if err := s.Create(Project, "!!!", CreateOptions{NoEdit: true}); err == nil {
+		t.Errorf("create with a title without letters or digits: want an error")
+	}
+	if _, err := os.Stat(filepath.Join(s.config.BaseDir, "projects", ".md")); err == nil {
+		t.Errorf("created projects/.md")
+	}
+}
@@ -0,0 +1,149 @@
+package notes
+
+import (
+	"fmt"
+	"time"
+)
+
+// linkSubtasks fills in the parent/child links of tasks from one file,
+// using list indentation, and rolls subtask progress, estimates and logged
+// time up to every ancestor. tasks must be in file order.
+func linkSubtasks(tasks []TaskInfo) {
+	var stack []int
+	for i := range tasks {
+		for len(stack) > 0 && tasks[stack[len(stack)-1]].Indent >= tasks[i].Indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			tasks[i].Parent = tasks[parent].Line
+			tasks[i].Depth = len(stack)
+			tasks[parent].Children = append(tasks[parent].Children, tasks[i].Line)
+		}
+		stack = append(stack, i)
+	}
+
+	// Children always follow their parent, so walking backwards lets each
+	// task add its finished roll-up to its parent
+	byLine := make(map[int]int, len(tasks))
+	for i := range tasks {
+		byLine[tasks[i].Line] = i
+		tasks[i].RollupTime = tasks[i].TotalTime
+		if estimate, err := parseDuration(tasks[i].Estimate); err == nil {
+			tasks[i].RollupEstimate = estimate
+		}
+	}
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].Parent == 0 {
+			continue
+		}
+		parent := &tasks[byLine[tasks[i].Parent]]
+		parent.RollupTime += tasks[i].RollupTime
+		parent.SubtasksTotal += tasks[i].SubtasksTotal
+		parent.SubtasksDone += tasks[i].SubtasksDone
+		// Cancelled subtasks keep their logged time but no longer count
+		// towards progress or the estimate
+		if tasks[i].Status != StatusCancelled {
+			parent.RollupEstimate += tasks[i].RollupEstimate
+			parent.SubtasksTotal++
+			if tasks[i].Status == StatusDone {
+				parent.SubtasksDone++
+			}
+		}
+	}
+}
+
+// taskKey identifies a task across files
+func taskKey(filePath string, line int) string {
+	return fmt.Sprintf("%s:%d", filePath, line)
+}
+
+// withParents adds the ancestors of every selected task from all, so a
+// matching subtask is shown with the tasks it belongs to. The returned set
+// marks the tasks that were only added for context.
+func withParents(selected, all []TaskInfo) ([]TaskInfo, map[string]bool) {
+	index := make(map[string]TaskInfo, len(all))
+	for _, task := range all {
+		index[taskKey(task.FilePath, task.Line)] = task
+	}
+
+	present := make(map[string]bool, len(selected))
+	for _, task := range selected {
+		present[taskKey(task.FilePath, task.Line)] = true
+	}
+
+	context := make(map[string]bool)
+	result := append([]TaskInfo{}, selected...)
+	for _, task := range selected {
+		for parent := task.Parent; parent != 0; {
+			key := taskKey(task.FilePath, parent)
+			ancestor, ok := index[key]
+			if !ok || present[key] {
+				break
+			}
+			present[key] = true
+			context[key] = true
+			result = append(result, ancestor)
+			parent = ancestor.Parent
+		}
+	}
+	return result, context
+}
+
+// nestSubtasks reorders sorted tasks so each subtask directly follows its
+// parent when both are shown, keeping the sort order among siblings and
+// top-level tasks. It returns the display depth of each task.
+func nestSubtasks(tasks []TaskInfo) ([]TaskInfo, []int) {
+	shown := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		shown[taskKey(task.FilePath, task.Line)] = true
+	}
+
+	children := make(map[string][]TaskInfo)
+	var roots []TaskInfo
+	for _, task := range tasks {
+		parentKey := taskKey(task.FilePath, task.Parent)
+		if task.Parent != 0 && shown[parentKey] {
+			children[parentKey] = append(children[parentKey], task)
+			continue
+		}
+		roots = append(roots, task)
+	}
+
+	ordered := make([]TaskInfo, 0, len(tasks))
+	depths := make([]int, 0, len(tasks))
+	var visit func(task TaskInfo, depth int)
+	visit = func(task TaskInfo, depth int) {
+		ordered = append(ordered, task)
+		depths = append(depths, depth)
+		for _, child := range children[taskKey(task.FilePath, task.Line)] {
+			visit(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		visit(root, 0)
+	}
+	return ordered, depths
+}
+
+// subtaskProgress formats a parent's subtask count, e.g. " [3/5]"
+func subtaskProgress(task TaskInfo) string {
+	if task.SubtasksTotal == 0 {
+		return ""
+	}
+	color := "\033[36m"
+	if task.SubtasksDone == task.SubtasksTotal {
+		color = "\033[32m"
+	}
+	return fmt.Sprintf(" %s[%d/%d]\033[0m", color, task.SubtasksDone, task.SubtasksTotal)
+}
+
+// effortFor returns the time worked and estimate to show for a task,
+// including its subtasks
+func effortFor(task TaskInfo) (time.Duration, time.Duration) {
+	if len(task.Children) == 0 {
+		estimate, _ := parseDuration(task.Estimate)
+		return task.TotalTime, estimate
+	}
+	return task.RollupTime, task.RollupEstimate
+}
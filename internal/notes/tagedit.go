@@ -0,0 +1,381 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"notes/internal/frontmatter"
+)
+
+var (
+	// frontmatterTagsPattern matches the tags key of a frontmatter block,
+	// capturing an inline value (a [flow, list] or comma-separated text)
+	frontmatterTagsPattern = regexp.MustCompile(`^tags:\s*(.*)$`)
+	// frontmatterItemPattern matches an item of a block list
+	frontmatterItemPattern = regexp.MustCompile(`^(\s*-\s*)(.*)$`)
+)
+
+// tagMapper decides what a tag becomes: the new tag with its #, or "" to
+// remove it. ok is false for tags the operation doesn't touch.
+type tagMapper func(tag string) (replacement string, ok bool)
+
+// renameTag maps from, and every tag nested below it, to to: renaming
+// #backend to #platform turns #backend/api into #platform/api
+func renameTag(from, to string) tagMapper {
+	from = strings.Trim(strings.TrimPrefix(from, "#"), "/")
+	to = strings.Trim(strings.TrimPrefix(to, "#"), "/")
+	return func(tag string) (string, bool) {
+		if !tagMatches(tag, from) {
+			return "", false
+		}
+		return "#" + to + strings.TrimPrefix(tag, "#")[len(from):], true
+	}
+}
+
+// mergeTags maps each of sources, and the tags nested below them, into
+// target
+func mergeTags(sources []string, target string) tagMapper {
+	renames := make([]tagMapper, len(sources))
+	for i, source := range sources {
+		renames[i] = renameTag(source, target)
+	}
+	return func(tag string) (string, bool) {
+		for _, rename := range renames {
+			if replacement, ok := rename(tag); ok {
+				return replacement, true
+			}
+		}
+		return "", false
+	}
+}
+
+// removeTag drops tag and every tag nested below it
+func removeTag(tag string) tagMapper {
+	return func(other string) (string, bool) {
+		return "", tagMatches(other, tag)
+	}
+}
+
+// tagChange is one line rewritten by a tag operation
+type tagChange struct {
+	line          int
+	before, after string
+	removed       bool
+}
+
+// tagFileEdit is the rewritten content of a note and what changed in it
+type tagFileEdit struct {
+	path    string
+	lines   []string
+	changes []tagChange
+}
+
+// planTagEdit rewrites the tags of every note in memory, in frontmatter and
+// in the text outside code blocks and code spans, and returns the notes
+// that change
+func (s *Service) planTagEdit(mapTag tagMapper) []tagFileEdit {
+	var edits []tagFileEdit
+	s.walkNotes(func(path string) {
+		lines, err := readLines(path)
+		if err != nil {
+			return
+		}
+
+		fm, _, _ := frontmatter.Parse([]byte(strings.Join(lines, "\n")))
+		skipLines := 0
+		if fm != nil {
+			skipLines = fm.Lines
+		}
+
+		edit := tagFileEdit{path: path}
+		var kept []string
+		inFence := false
+		var tagList map[string]bool
+		for i, line := range lines {
+			updated, drop := line, false
+			switch {
+			case i < skipLines && (i == 0 || i == skipLines-1):
+				// frontmatter delimiters
+			case i < skipLines:
+				updated, drop, tagList = rewriteFrontmatterTags(line, tagList, mapTag)
+			case codeFencePattern.MatchString(line):
+				inFence = !inFence
+			case !inFence:
+				updated = rewriteLineTags(line, mapTag)
+			}
+
+			if drop || updated != line {
+				edit.changes = append(edit.changes, tagChange{line: i + 1, before: line, after: updated, removed: drop})
+			}
+			if !drop {
+				kept = append(kept, updated)
+			}
+		}
+
+		if len(edit.changes) > 0 {
+			edit.lines = kept
+			edits = append(edits, edit)
+		}
+	})
+	return edits
+}
+
+// rewriteLineTags applies mapTag to the tags in a line of text, leaving
+// code spans alone. A tag that ends up on the line twice is kept once, and
+// a removed tag takes one neighbouring space with it.
+func rewriteLineTags(line string, mapTag tagMapper) string {
+	// Blank out code spans so match positions still line up with the line
+	masked := codeSpanPattern.ReplaceAllStringFunc(line, func(span string) string {
+		return strings.Repeat(" ", len(span))
+	})
+
+	matches := tagPattern.FindAllStringSubmatchIndex(masked, -1)
+	present := make(map[string]bool)
+	for _, tag := range extractTags(line) {
+		if _, ok := mapTag(tag); !ok {
+			present[strings.ToLower(tag)] = true
+		}
+	}
+
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, match := range matches {
+		start, end := match[2]-1, match[3]
+		tag := "#" + strings.TrimRight(line[match[2]:end], "/-")
+		end = start + len(tag)
+		if !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		newTag, ok := mapTag(tag)
+		if !ok {
+			continue
+		}
+		if newTag != "" && present[strings.ToLower(newTag)] {
+			newTag = ""
+		}
+		if newTag != "" {
+			present[strings.ToLower(newTag)] = true
+		}
+		replacements = append(replacements, replacement{start, end, newTag})
+	}
+
+	for i := len(replacements) - 1; i >= 0; i-- {
+		r := replacements[i]
+		start, end := r.start, r.end
+		if r.text == "" {
+			if start > 0 && line[start-1] == ' ' {
+				start--
+			} else if end < len(line) && line[end] == ' ' {
+				end++
+			}
+		}
+		line = line[:start] + r.text + line[end:]
+	}
+	return line
+}
+
+// rewriteFrontmatterTags applies mapTag to a frontmatter line if it holds
+// tags: the tags key itself, or an item of its block list. tagList is nil
+// unless the previous lines opened a block list of tags, and then holds the
+// tags already in it. drop reports that the line should be deleted.
+func rewriteFrontmatterTags(line string, tagList map[string]bool, mapTag tagMapper) (updated string, drop bool, nextList map[string]bool) {
+	if match := frontmatterTagsPattern.FindStringSubmatch(line); match != nil {
+		value := strings.TrimSpace(match[1])
+		if value == "" {
+			return line, false, make(map[string]bool)
+		}
+		flow := strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]")
+		items := mapFrontmatterTags(strings.Split(strings.Trim(value, "[]"), ","), make(map[string]bool), mapTag)
+		if flow || len(items) == 0 {
+			return "tags: [" + strings.Join(items, ", ") + "]", false, nil
+		}
+		return "tags: " + strings.Join(items, ", "), false, nil
+	}
+
+	if tagList != nil {
+		match := frontmatterItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			return line, false, nil
+		}
+		items := mapFrontmatterTags([]string{match[2]}, tagList, mapTag)
+		if len(items) == 0 {
+			return line, true, tagList
+		}
+		return match[1] + items[0], false, tagList
+	}
+	return line, false, nil
+}
+
+// mapFrontmatterTags applies mapTag to tag list items, which are written
+// without a # unless the note's author added one, and drops the ones
+// already in seen
+func mapFrontmatterTags(items []string, seen map[string]bool, mapTag tagMapper) []string {
+	var result []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name := strings.Trim(item, `"'`)
+		hash := strings.HasPrefix(name, "#")
+
+		if newTag, ok := mapTag("#" + strings.TrimPrefix(name, "#")); ok {
+			if newTag == "" {
+				continue
+			}
+			item = strings.TrimPrefix(newTag, "#")
+			if hash {
+				item = `"` + newTag + `"`
+			}
+			name = newTag
+		}
+
+		key := strings.ToLower(strings.TrimPrefix(name, "#"))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// applyTagEdit shows the changes a tag operation makes, asks before
+// writing them unless yes is set, and commits the rewritten notes
+func (s *Service) applyTagEdit(mapTag tagMapper, message string, dryRun, yes bool) error {
+	edits := s.planTagEdit(mapTag)
+	if len(edits) == 0 {
+		fmt.Printf("\033[90mNo notes use that tag.\033[0m\n")
+		return nil
+	}
+
+	changed := 0
+	for _, edit := range edits {
+		relPath, _ := filepath.Rel(s.config.BaseDir, edit.path)
+		for _, change := range edit.changes {
+			fmt.Printf("\033[1;34m%s:L%d\033[0m\n", relPath, change.line)
+			fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(change.before))
+			if !change.removed {
+				fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(change.after))
+			}
+			changed++
+		}
+	}
+	summary := fmt.Sprintf("%d line%s in %d note%s", changed, pluralize(changed), len(edits), pluralize(len(edits)))
+
+	if dryRun {
+		fmt.Printf("\n\033[90m%s would change (dry run)\033[0m\n", summary)
+		return nil
+	}
+	if !yes {
+		if !isInteractive() {
+			return fmt.Errorf("%s would change; re-run with --yes to apply", summary)
+		}
+		answer, err := promptLine(fmt.Sprintf("\nApply: %s? [y/N] ", summary))
+		if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Printf("Cancelled; nothing was changed\n")
+			return nil
+		}
+	}
+
+	var paths []string
+	for _, edit := range edits {
+		if err := writeLines(edit.path, edit.lines); err != nil {
+			return err
+		}
+		paths = append(paths, edit.path)
+	}
+	fmt.Printf("✅ %s: %s\n", message, summary)
+
+	if s.config.Git.AutoCommit {
+		if err := s.commitFiles(paths, message); err != nil {
+			fmt.Printf("⚠ Warning: Failed to commit changes to git: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// HandleTagsCommand lists the tag tree, or renames, merges or removes tags
+// across the vault
+func (s *Service) HandleTagsCommand(args []string) error {
+	if len(args) == 0 {
+		return s.ShowTags("")
+	}
+
+	var words []string
+	var into string
+	dryRun, yes := false, false
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--yes", "-y":
+			yes = true
+		case "--into":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--into requires a tag")
+			}
+			i++
+			into = args[i]
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			words = append(words, arg)
+		}
+	}
+
+	switch args[0] {
+	case "rename":
+		if len(words) != 2 {
+			return fmt.Errorf("usage: notes tags rename <old> <new>")
+		}
+		if err := validateTagName(words[1]); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("Rename tag %s to %s", hashTag(words[0]), hashTag(words[1]))
+		return s.applyTagEdit(renameTag(words[0], words[1]), message, dryRun, yes)
+	case "merge":
+		if len(words) == 0 || into == "" {
+			return fmt.Errorf("usage: notes tags merge <tag>... --into <tag>")
+		}
+		if err := validateTagName(into); err != nil {
+			return err
+		}
+		sources := make([]string, len(words))
+		for i, word := range words {
+			sources[i] = hashTag(word)
+		}
+		message := fmt.Sprintf("Merge tags %s into %s", strings.Join(sources, ", "), hashTag(into))
+		return s.applyTagEdit(mergeTags(words, into), message, dryRun, yes)
+	case "rm", "remove":
+		if len(words) != 1 {
+			return fmt.Errorf("usage: notes tags rm <tag>")
+		}
+		message := fmt.Sprintf("Remove tag %s", hashTag(words[0]))
+		return s.applyTagEdit(removeTag(words[0]), message, dryRun, yes)
+	default:
+		if len(args) > 1 {
+			return fmt.Errorf("unknown tags command: %s", args[0])
+		}
+		return s.ShowTags(args[0])
+	}
+}
+
+// validateTagName checks that a new tag name will be read back as a tag
+func validateTagName(name string) error {
+	if tags := extractTags(hashTag(name)); len(tags) != 1 || tags[0] != hashTag(name) {
+		return fmt.Errorf("invalid tag name: %s (use letters, digits, _ and -, nested with /)", name)
+	}
+	return nil
+}
+
+func hashTag(name string) string {
+	return "#" + strings.Trim(strings.TrimPrefix(name, "#"), "/")
+}
@@ -0,0 +1,140 @@
+package notes
+
+import (
+	"testing"
+)
+
+func TestTagsCommand(t *testing.T) {
+	note := lines(
+		"---",
+		"title: API",
+		"tags:",
+		"  - backend",
+		"  - backend/api",
+		"  - ops",
+		"---",
+		"# API #backend",
+		"- [ ] Add paging #backend/api #ops",
+		"- [ ] Deploy #devops #ops",
+		"Use `#backend` in code spans",
+		"```",
+		"#backend stays in code",
+		"```",
+		"Not #backends")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "rename",
+			args: []string{"rename", "backend", "platform"},
+			want: lines(
+				"---",
+				"title: API",
+				"tags:",
+				"  - platform",
+				"  - platform/api",
+				"  - ops",
+				"---",
+				"# API #platform",
+				"- [ ] Add paging #platform/api #ops",
+				"- [ ] Deploy #devops #ops",
+				"Use `#backend` in code spans",
+				"```",
+				"#backend stays in code",
+				"```",
+				"Not #backends"),
+		},
+		{
+			name: "merge",
+			args: []string{"merge", "devops", "#backend", "--into", "ops"},
+			want: lines(
+				"---",
+				"title: API",
+				"tags:",
+				"  - ops",
+				"  - ops/api",
+				"---",
+				"# API #ops",
+				"- [ ] Add paging #ops/api #ops",
+				"- [ ] Deploy #ops",
+				"Use `#backend` in code spans",
+				"```",
+				"#backend stays in code",
+				"```",
+				"Not #backends"),
+		},
+		{
+			name: "rm",
+			args: []string{"rm", "#backend"},
+			want: lines(
+				"---",
+				"title: API",
+				"tags:",
+				"  - ops",
+				"---",
+				"# API",
+				"- [ ] Add paging #ops",
+				"- [ ] Deploy #devops #ops",
+				"Use `#backend` in code spans",
+				"```",
+				"#backend stays in code",
+				"```",
+				"Not #backends"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestVault(t, map[string]string{"projects/api.md": note})
+
+			if err := s.HandleTagsCommand(append(tt.args, "--dry-run")); err != nil {
+				t.Fatalf("tags %s --dry-run: %v", tt.name, err)
+			}
+			if got := readNote(t, s, "projects/api.md"); got != note {
+				t.Errorf("dry run changed the note:\n%s", got)
+			}
+
+			if err := s.HandleTagsCommand(append(tt.args, "--yes")); err != nil {
+				t.Fatalf("tags %s --yes: %v", tt.name, err)
+			}
+			if got := readNote(t, s, "projects/api.md"); got != tt.want {
+				t.Errorf("note =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagsFlowList(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/web.md": lines("---", "tags: [web, \"#frontend\", css]", "---", "#frontend work"),
+	})
+	if err := s.HandleTagsCommand([]string{"merge", "frontend", "css", "--into", "web", "--yes"}); err != nil {
+		t.Fatalf("tags merge: %v", err)
+	}
+	want := lines("---", "tags: [web]", "---", "#web work")
+	if got := readNote(t, s, "projects/web.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTagsCommandInvalid(t *testing.T) {
+	s := newTestVault(t, map[string]string{"projects/api.md": lines("#backend")})
+	for _, args := range [][]string{
+		{"rename", "backend"},
+		{"rename", "backend", "has space"},
+		{"rename", "backend", "123"},
+		{"merge", "backend"},
+		{"rm"},
+		{"rename", "backend", "platform", "--force"},
+	} {
+		if err := s.HandleTagsCommand(args); err == nil {
+			t.Errorf("tags %v: want an error", args)
+		}
+	}
+	if got := readNote(t, s, "projects/api.md"); got != lines("#backend") {
+		t.Errorf("note changed:\n%s", got)
+	}
+}
@@ -0,0 +1,216 @@
+package notes
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"notes/internal/frontmatter"
+)
+
+var (
+	// tagPattern matches #tags made of letters and digits in any script,
+	// _ and -, nested with / (#work/backend). The # must start a word, so
+	// C# and URL fragments don't count.
+	tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_][\p{L}\p{N}_/-]*)`)
+	// codeSpanPattern matches inline code, which never holds tags
+	codeSpanPattern = regexp.MustCompile("`+[^`]*`+")
+	// codeFencePattern matches the start or end of a fenced code block
+	codeFencePattern = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// extractTags returns the tags in a line of text, with their leading #, in
+// order and without repeats. Tags inside code spans and pure numbers such
+// as issue references (#42) are skipped.
+func extractTags(text string) []string {
+	text = codeSpanPattern.ReplaceAllString(text, " ")
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, match := range tagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.TrimRight(match[1], "/-")
+		if !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, "#"+tag)
+	}
+	return tags
+}
+
+// tagMatches reports whether tag is want or nested below it, so #work
+// matches #work/backend. Tags compare case-insensitively.
+func tagMatches(tag, want string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	want = strings.ToLower(strings.Trim(strings.TrimPrefix(want, "#"), "/"))
+	return tag == want || strings.HasPrefix(tag, want+"/")
+}
+
+// matchesAnyTag reports whether any of tags is, or is nested below, any of
+// wanted
+func matchesAnyTag(tags, wanted []string) bool {
+	for _, want := range wanted {
+		for _, tag := range tags {
+			if tagMatches(tag, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tagNode is one level of the tag tree printed by notes tags
+type tagNode struct {
+	name     string
+	children map[string]*tagNode
+	notes    map[string]bool
+	tasks    map[string]bool
+}
+
+func newTagNode(name string) *tagNode {
+	return &tagNode{
+		name:     name,
+		children: make(map[string]*tagNode),
+		notes:    make(map[string]bool),
+		tasks:    make(map[string]bool),
+	}
+}
+
+// add counts a note, and optionally a task in it, for a tag and every
+// tag above it
+func (n *tagNode) add(tag, note, task string) {
+	node := n
+	for _, part := range strings.Split(strings.TrimPrefix(tag, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		key := strings.ToLower(part)
+		child, ok := node.children[key]
+		if !ok {
+			child = newTagNode(part)
+			node.children[key] = child
+		}
+		child.notes[note] = true
+		if task != "" {
+			child.tasks[task] = true
+		}
+		node = child
+	}
+}
+
+func (n *tagNode) sortedChildren() []*tagNode {
+	children := make([]*tagNode, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return strings.ToLower(children[i].name) < strings.ToLower(children[j].name)
+	})
+	return children
+}
+
+// noteTags returns every tag used in a note: its frontmatter tags and the
+// tags in its text outside code blocks
+func noteTags(content []byte) []string {
+	fm, _, _ := frontmatter.Parse(content)
+	skipLines := 0
+	if fm != nil {
+		skipLines = fm.Lines
+	}
+	tags := fm.Tags()
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNum := 0
+	inFence := false
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if lineNum <= skipLines {
+			continue
+		}
+		if codeFencePattern.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if !inFence {
+			tags = append(tags, extractTags(line)...)
+		}
+	}
+	return tags
+}
+
+// ShowTags prints every tag in the vault as a tree, with the number of
+// notes and open tasks using each tag or a tag nested below it. A prefix
+// limits the tree to one branch.
+func (s *Service) ShowTags(prefix string) error {
+	root := newTagNode("")
+
+	s.walkNotes(func(path string) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return
+		}
+		for _, tag := range noteTags(content) {
+			if prefix == "" || tagMatches(tag, prefix) {
+				root.add(tag, path, "")
+			}
+		}
+		for _, task := range s.extractTasks(path) {
+			if !task.Status.IsOpen() {
+				continue
+			}
+			key := taskKey(task.FilePath, task.Line)
+			for _, tag := range append(append([]string{}, task.Tags...), task.NoteTags...) {
+				if prefix == "" || tagMatches(tag, prefix) {
+					root.add(tag, path, key)
+				}
+			}
+		}
+	})
+
+	fmt.Printf("\033[1;36m🏷  Tags\033[0m\n")
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+
+	if len(root.children) == 0 {
+		if prefix != "" {
+			fmt.Printf("\033[90mNo tags under #%s.\033[0m\n", strings.TrimPrefix(prefix, "#"))
+		} else {
+			fmt.Printf("\033[90mNo tags found.\033[0m\n")
+		}
+		return nil
+	}
+
+	for _, node := range root.sortedChildren() {
+		printTagNode(node, "#", "", "")
+	}
+
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+	fmt.Printf("\033[90mFilter with: notes tasks --tag <tag> (includes nested tags)\033[0m\n")
+	return nil
+}
+
+func printTagNode(node *tagNode, label, branch, indent string) {
+	counts := fmt.Sprintf("%d note%s", len(node.notes), pluralize(len(node.notes)))
+	if len(node.tasks) > 0 {
+		counts += fmt.Sprintf(" · %d open task%s", len(node.tasks), pluralize(len(node.tasks)))
+	}
+	fmt.Printf("%s\033[36m%s%s\033[0m \033[90m(%s)\033[0m\n", branch, label, node.name, counts)
+
+	children := node.sortedChildren()
+	for i, child := range children {
+		if i == len(children)-1 {
+			printTagNode(child, "", indent+"└─ ", indent+"   ")
+		} else {
+			printTagNode(child, "", indent+"├─ ", indent+"│  ")
+		}
+	}
+}
@@ -0,0 +1,640 @@
+package notes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// taskMarkPattern matches the list dash and checkbox at the start of a task
+var taskMarkPattern = regexp.MustCompile(`^(\s*-\s*)\[[^\]]*\]`)
+
+// taskTokenKeys are the key:value tokens that carry task metadata. They are
+// kept when a task's text is replaced.
+var taskTokenKeys = []string{"due", "est", "every", "start", "scheduled", "waiting", "p", "id", "after", "blocks"}
+
+// taskSectionPattern matches the headings new tasks are filed under
+var taskSectionPattern = regexp.MustCompile(`(?i)^#{2,}\s*(tasks|actions|action items|todos?)\s*$`)
+
+// HandleTaskCommand processes notes task subcommands
+func (s *Service) HandleTaskCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("task command requires a subcommand")
+	}
+
+	command := args[0]
+	commandArgs := args[1:]
+
+	switch command {
+	case "done":
+		query, _, err := parseTaskArgs(commandArgs, nil)
+		if err != nil {
+			return err
+		}
+		return s.completeTask(query)
+	case "add":
+		text, flags, err := parseTaskArgs(commandArgs, []string{"--to", "--due", "--est", "--tag", "--priority", "--start", "--scheduled", "--waiting"})
+		if err != nil {
+			return err
+		}
+		return s.addTask(text, flags)
+	case "edit":
+		query, flags, err := parseTaskArgs(commandArgs, []string{"--text", "--due", "--est", "--tag", "--untag", "--priority", "--start", "--scheduled", "--waiting"})
+		if err != nil {
+			return err
+		}
+		return s.editTask(query, flags)
+	case "due":
+		if len(commandArgs) < 2 {
+			return fmt.Errorf("usage: notes task due <query|id> <date>")
+		}
+		query := strings.Join(commandArgs[:len(commandArgs)-1], " ")
+		return s.rescheduleTask(query, commandArgs[len(commandArgs)-1])
+	default:
+		return fmt.Errorf("unknown task command: %s", command)
+	}
+}
+
+// parseTaskArgs splits arguments into the free text (a query or task text)
+// and the values of the given flags. Flags may repeat.
+func parseTaskArgs(args []string, valueFlags []string) (string, map[string][]string, error) {
+	flags := make(map[string][]string)
+	var words []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			words = append(words, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		known := false
+		for _, flag := range valueFlags {
+			if flag == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", nil, fmt.Errorf("unknown flag: %s", name)
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		flags[name] = append(flags[name], value)
+	}
+
+	text := strings.TrimSpace(strings.Join(words, " "))
+	if text == "" {
+		return "", nil, fmt.Errorf("a task query or text is required")
+	}
+	return text, flags, nil
+}
+
+// lastFlag returns the final value given for a flag
+func lastFlag(flags map[string][]string, name string) (string, bool) {
+	values := flags[name]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// completeTask marks a task done, stopping and logging its timer first if
+// it is the one being timed
+func (s *Service) completeTask(query string) error {
+	task, err := s.findTaskByText(query)
+	if err != nil {
+		return err
+	}
+
+	task, next, err := s.finishTask(task, true)
+	if err != nil {
+		return err
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+	fmt.Printf("✅ Completed: \033[1m%s\033[0m\n", task.Text)
+	fmt.Printf("\033[90mLocation: %s:L%d\033[0m\n", relPath, task.Line)
+	if next != "" {
+		fmt.Printf("🔁 Next occurrence due %s\n", next)
+	}
+	return nil
+}
+
+// finishTask stops and logs the timer if it is running on task, then marks
+// the task done. interactive is false when nobody is at the terminal to
+// describe the timed session. It returns the task as found again after the
+// time log was written, and the next due date for recurring tasks.
+func (s *Service) finishTask(task *TaskInfo, interactive bool) (*TaskInfo, string, error) {
+	if state, err := s.loadTimerState(); err == nil && state.IsActive && timerIsFor(state, task) {
+		description := ""
+		if !interactive && state.Description == "" {
+			description = defaultSessionDescription
+		}
+		if err := s.stopTimer(description); err != nil {
+			return nil, "", fmt.Errorf("failed to stop timer: %w", err)
+		}
+		// The time log was written below the task; find it again
+		if task, err = s.locateTask(task.FilePath, task.ID, task.Text); err != nil {
+			return nil, "", err
+		}
+	}
+
+	next, err := s.markTaskDone(task)
+	if err != nil {
+		return nil, "", err
+	}
+	return task, next, nil
+}
+
+// markTaskDone checks off a task and, if it recurs, adds its next
+// occurrence below it. It returns the next due date for recurring tasks.
+func (s *Service) markTaskDone(task *TaskInfo) (string, error) {
+	var rule *Recurrence
+	if task.Recurrence != "" {
+		var err error
+		if rule, err = parseRecurrence(task.Recurrence); err != nil {
+			return "", err
+		}
+	}
+
+	if err := s.updateTaskLine(task, func(line string) string {
+		return setTaskMark(line, StatusDone)
+	}); err != nil {
+		return "", err
+	}
+	if err := s.syncTimeSummary(task.FilePath, task.Line); err != nil {
+		return "", err
+	}
+
+	if rule == nil {
+		return "", nil
+	}
+	return s.insertNextOccurrence(task, rule)
+}
+
+// ToggleTask flips the task on the given line of a note between open and
+// done. It backs the checkboxes in the preview.
+func (s *Service) ToggleTask(filePath string, line int) error {
+	var task *TaskInfo
+	tasks := s.extractTasks(filePath)
+	for i := range tasks {
+		if tasks[i].Line == line {
+			task = &tasks[i]
+		}
+	}
+	if task == nil {
+		return fmt.Errorf("no task on line %d; reload the page", line)
+	}
+
+	switch {
+	case task.Status.IsOpen():
+		_, _, err := s.finishTask(task, false)
+		return err
+	case task.Status == StatusDone:
+		if err := s.updateTaskLine(task, func(line string) string {
+			return setTaskMark(line, StatusTodo)
+		}); err != nil {
+			return err
+		}
+		return s.syncTimeSummary(task.FilePath, task.Line)
+	default:
+		return fmt.Errorf("%s tasks can't be toggled", task.Status)
+	}
+}
+
+func timerIsFor(state TimerState, task *TaskInfo) bool {
+	if state.FilePath != task.FilePath {
+		return false
+	}
+	if state.TaskID != "" && task.ID != "" {
+		return state.TaskID == task.ID
+	}
+	return state.TaskText == task.Text
+}
+
+// addTask writes a new task into a note, under its tasks section when it
+// has one
+func (s *Service) addTask(text string, flags map[string][]string) error {
+	target, _ := lastFlag(flags, "--to")
+	if target == "" {
+		target = "daily"
+	}
+	filePath, err := s.resolveTaskTarget(target)
+	if err != nil {
+		return err
+	}
+
+	line := "- [ ] " + text
+	dates, err := parseDateFlags(flags, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, key := range dateTokenKeys {
+		if value, ok := dates[key]; ok {
+			line = setTaskToken(line, key, value)
+		}
+	}
+	if person, ok := lastFlag(flags, "--waiting"); ok {
+		line = setTaskToken(line, "waiting", waitingValue(person))
+	}
+	if value, ok := lastFlag(flags, "--est"); ok {
+		if _, err := parseDuration(value); err != nil {
+			return fmt.Errorf("invalid estimate %q (use e.g. 30m, 2h, 1h30m)", value)
+		}
+		line = setTaskToken(line, "est", value)
+	}
+	if value, ok := lastFlag(flags, "--priority"); ok {
+		priority, err := parsePriorityValue(value)
+		if err != nil {
+			return err
+		}
+		line = setTaskPriority(line, priority)
+	}
+	for _, tag := range flags["--tag"] {
+		line = addTaskTag(line, tag)
+	}
+
+	lineNum, err := insertTask(filePath, line)
+	if err != nil {
+		return err
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, filePath)
+	fmt.Printf("✅ Added task: \033[1m%s\033[0m\n", text)
+	fmt.Printf("\033[90mLocation: %s:L%d\033[0m\n", relPath, lineNum)
+	return nil
+}
+
+// resolveTaskTarget turns --to into a note path: "daily" is today's daily
+// note (created if needed), otherwise the path of a note in one of the
+// note folders or a fuzzy note query
+func (s *Service) resolveTaskTarget(target string) (string, error) {
+	if target == "daily" {
+		return s.ensureDailyNote(time.Now())
+	}
+
+	for _, candidate := range []string{target, target + ".md"} {
+		path := filepath.Join(s.config.BaseDir, candidate)
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		if !isNoteFile(path) || !s.inScanDirs(path) {
+			return "", fmt.Errorf("--to must be a .md or .txt note in one of the note folders (%s): %s", strings.Join(s.config.ScanDirs(), ", "), target)
+		}
+		return path, nil
+	}
+
+	matches := s.findNotes(target)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no note matches: %s", target)
+	}
+	return matches[0].Path, nil
+}
+
+// ensureDailyNote returns the path of the daily note for day, creating it
+// from the daily template if it does not exist yet
+func (s *Service) ensureDailyNote(day time.Time) (string, error) {
+	nt, err := s.lookupNoteType(Daily)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(s.config.BaseDir, nt.Dir)
+	path := filepath.Join(dir, nt.FileName("", day))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	content, err := s.renderNote(nt, "", nil)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to create daily note: %w", err)
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, path)
+	fmt.Printf("✅ Created new daily note: %s\n", relPath)
+	return path, nil
+}
+
+// insertTask adds a task line to a file and returns its line number. An
+// empty "- [ ]" placeholder is filled in first; otherwise the task goes at
+// the end of the first Tasks/Actions section, or the end of the file.
+func insertTask(filePath, taskLine string) (int, error) {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, line := range lines {
+		if match := taskLinePattern.FindStringSubmatch(line); match != nil && match[2] == "" && strings.TrimSpace(match[3]) == "" {
+			lines[i] = match[1] + taskLine
+			return i + 1, writeLines(filePath, lines)
+		}
+	}
+
+	insertAt := -1
+	for i, line := range lines {
+		if !taskSectionPattern.MatchString(strings.TrimSpace(line)) {
+			continue
+		}
+		insertAt = i + 1
+		for j := i + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(trimmed, "#") {
+				break
+			}
+			if trimmed != "" {
+				insertAt = j + 1
+			}
+		}
+		break
+	}
+
+	if insertAt < 0 {
+		// Append, dropping trailing blank lines so the task joins the text
+		end := len(lines)
+		for end > 0 && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		lines = append(lines[:end], taskLine, "")
+		return end + 1, writeLines(filePath, lines)
+	}
+
+	newLines := make([]string, 0, len(lines)+1)
+	newLines = append(newLines, lines[:insertAt]...)
+	newLines = append(newLines, taskLine)
+	newLines = append(newLines, lines[insertAt:]...)
+	return insertAt + 1, writeLines(filePath, newLines)
+}
+
+// editTask rewrites a task's text or metadata, or opens it in the editor
+// when no changes are given
+func (s *Service) editTask(query string, flags map[string][]string) error {
+	task, err := s.findTaskByText(query)
+	if err != nil {
+		return err
+	}
+
+	if len(flags) == 0 {
+		return s.openEditor(task.FilePath, task.Line)
+	}
+
+	dates, err := parseDateFlags(flags, time.Now())
+	if err != nil {
+		return err
+	}
+	if value, ok := lastFlag(flags, "--est"); ok && value != "none" {
+		if _, err := parseDuration(value); err != nil {
+			return fmt.Errorf("invalid estimate %q (use e.g. 30m, 2h, 1h30m)", value)
+		}
+	}
+	var priority Priority
+	if value, ok := lastFlag(flags, "--priority"); ok {
+		if priority, err = parsePriorityValue(value); err != nil {
+			return err
+		}
+	}
+
+	var updated string
+	err = s.updateTaskLine(task, func(line string) string {
+		if text, ok := lastFlag(flags, "--text"); ok {
+			line = replaceTaskText(line, text)
+		}
+		for _, key := range dateTokenKeys {
+			if value, ok := dates[key]; ok {
+				line = setTaskToken(line, key, value)
+			}
+		}
+		if person, ok := lastFlag(flags, "--waiting"); ok {
+			line = setTaskToken(line, "waiting", waitingValue(person))
+		}
+		if value, ok := lastFlag(flags, "--est"); ok {
+			if value == "none" {
+				value = ""
+			}
+			line = setTaskToken(line, "est", value)
+		}
+		if _, ok := lastFlag(flags, "--priority"); ok {
+			line = setTaskPriority(line, priority)
+		}
+		for _, tag := range flags["--tag"] {
+			line = addTaskTag(line, tag)
+		}
+		for _, tag := range flags["--untag"] {
+			line = removeTaskTag(line, tag)
+		}
+		updated = line
+		return line
+	})
+	if err != nil {
+		return err
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+	if match := taskLinePattern.FindStringSubmatch(updated); match != nil {
+		updated = match[3]
+	}
+	fmt.Printf("✏️  Updated task: \033[1m%s\033[0m\n", updated)
+	fmt.Printf("\033[90mLocation: %s:L%d\033[0m\n", relPath, task.Line)
+	return nil
+}
+
+// rescheduleTask sets or clears a task's due date
+func (s *Service) rescheduleTask(query, when string) error {
+	task, err := s.findTaskByText(query)
+	if err != nil {
+		return err
+	}
+
+	due, err := parseDueValue(when, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := s.updateTaskLine(task, func(line string) string {
+		return setTaskToken(line, "due", due)
+	}); err != nil {
+		return err
+	}
+
+	if due == "" {
+		fmt.Printf("📅 Cleared due date: \033[1m%s\033[0m\n", task.Text)
+	} else {
+		fmt.Printf("📅 Rescheduled: \033[1m%s\033[0m → %s\n", task.Text, due)
+	}
+	return nil
+}
+
+// updateTaskLine rewrites a single task line in place. Indentation and the
+// lines below the task, such as its time log, are left alone.
+func (s *Service) updateTaskLine(task *TaskInfo, update func(line string) string) error {
+	lines, err := readLines(task.FilePath)
+	if err != nil {
+		return err
+	}
+
+	index := task.Line - 1
+	if index < 0 || index >= len(lines) || !taskLinePattern.MatchString(lines[index]) {
+		return fmt.Errorf("task line %d changed; run the command again", task.Line)
+	}
+
+	lines[index] = update(lines[index])
+	return writeLines(task.FilePath, lines)
+}
+
+// setTaskMark changes a task's checkbox to the one for status
+func setTaskMark(line string, status TaskStatus) string {
+	return taskMarkPattern.ReplaceAllString(line, "${1}"+status.Mark())
+}
+
+// setTaskToken sets a key:value token on a task line, or removes it when
+// value is empty. New tokens go before the task's ^id anchor.
+func setTaskToken(line, key, value string) string {
+	pattern := regexp.MustCompile(`\s+` + regexp.QuoteMeta(key) + `:\S+`)
+	line = pattern.ReplaceAllString(line, "")
+	if value == "" {
+		return line
+	}
+	return appendTaskToken(line, key+":"+value)
+}
+
+func appendTaskToken(line, token string) string {
+	if loc := taskIDPattern.FindStringIndex(line); loc != nil {
+		return strings.TrimRight(line[:loc[0]], " \t") + " " + token + line[loc[0]:]
+	}
+	return strings.TrimRight(line, " \t") + " " + token
+}
+
+func addTaskTag(line, tag string) string {
+	tag = "#" + strings.TrimPrefix(tag, "#")
+	for _, field := range strings.Fields(line) {
+		if strings.EqualFold(field, tag) {
+			return line
+		}
+	}
+	return appendTaskToken(line, tag)
+}
+
+func removeTaskTag(line, tag string) string {
+	tag = strings.TrimPrefix(tag, "#")
+	pattern := regexp.MustCompile(`(?i)\s+#` + regexp.QuoteMeta(tag) + `(\s|$)`)
+	return pattern.ReplaceAllString(line, "$1")
+}
+
+// replaceTaskText swaps a task's description for text, keeping its
+// checkbox, metadata tokens, tags, @assignees and ^id
+func replaceTaskText(line, text string) string {
+	match := taskLinePattern.FindStringSubmatch(line)
+	if match == nil {
+		return line
+	}
+
+	// A todo.txt style (A) priority stays in front of the text
+	if letter := priorityLetterPattern.FindString(strings.TrimSpace(match[3])); letter != "" {
+		text = strings.TrimSpace(letter) + " " + text
+	}
+
+	given := make(map[string]bool)
+	for _, field := range strings.Fields(text) {
+		given[strings.ToLower(field)] = true
+	}
+	var kept []string
+	for _, field := range strings.Fields(match[3]) {
+		if isTaskToken(field) && !given[strings.ToLower(field)] {
+			kept = append(kept, field)
+		}
+	}
+
+	prefix := taskMarkPattern.FindString(line)
+	return strings.TrimRight(prefix+" "+strings.Join(append([]string{text}, kept...), " "), " ")
+}
+
+func isTaskToken(field string) bool {
+	if strings.HasPrefix(field, "#") || strings.HasPrefix(field, "^") {
+		return len(field) > 1
+	}
+	if strings.HasPrefix(field, "@") {
+		return mentionPattern.MatchString(field)
+	}
+	if priorityMarkerPattern.MatchString(field) {
+		return true
+	}
+	for _, key := range taskTokenKeys {
+		if strings.HasPrefix(field, key+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateFlags reads the --due, --start and --scheduled flags into the
+// values of their tokens, keyed by token name
+func parseDateFlags(flags map[string][]string, now time.Time) (map[string]string, error) {
+	dates := make(map[string]string)
+	for _, key := range dateTokenKeys {
+		value, ok := lastFlag(flags, "--"+key)
+		if !ok {
+			continue
+		}
+		date, err := parseDueValue(value, now)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", key, err)
+		}
+		dates[key] = date
+	}
+	return dates, nil
+}
+
+// waitingValue turns a --waiting argument into the token value: "@name",
+// or "" for none
+func waitingValue(person string) string {
+	person = strings.TrimPrefix(strings.TrimSpace(person), "@")
+	if person == "" || strings.EqualFold(person, "none") {
+		return ""
+	}
+	return "@" + person
+}
+
+// parsePriorityValue reads a --priority argument. "none" removes the
+// task's priority marker and is returned as "".
+func parsePriorityValue(value string) (Priority, error) {
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return "", nil
+	}
+	priority, ok := parsePriority(value)
+	if !ok {
+		return "", fmt.Errorf("invalid priority %q (use high, medium, low or none)", value)
+	}
+	return priority, nil
+}
+
+// parseDueValue turns a due date argument into the absolute form stored
+// in due: tokens, accepting everything parseDate does. "none" clears the
+// due date and is returned as "".
+func parseDueValue(value string, now time.Time) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "none", "clear", "":
+		return "", nil
+	}
+
+	date, hasTime, err := parseDate(value, now)
+	if err != nil {
+		return "", err
+	}
+	return formatDate(date, hasTime), nil
+}
@@ -0,0 +1,190 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTaskAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		args []string
+		want string
+	}{
+		{
+			name: "under the tasks section",
+			note: lines("# Project", "", "## Tasks", "- [ ] Existing", "", "## Notes", "Text"),
+			args: []string{"Write docs", "--to", "projects/p", "--due", "2024-05-01", "--est", "1h", "--tag", "docs"},
+			want: lines("# Project", "", "## Tasks", "- [ ] Existing", "- [ ] Write docs due:2024-05-01 est:1h #docs", "", "## Notes", "Text"),
+		},
+		{
+			name: "fills an empty placeholder",
+			note: lines("# Project", "- [ ] ", "- [ ] Other"),
+			args: []string{"Call Bob", "--to", "projects/p.md"},
+			want: lines("# Project", "- [ ] Call Bob", "- [ ] Other"),
+		},
+		{
+			name: "at the end without a section",
+			note: lines("# Project", "Some text", "", ""),
+			args: []string{"Ship it", "--to", "projects/p"},
+			want: lines("# Project", "Some text", "- [ ] Ship it"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestVault(t, map[string]string{"projects/p.md": tt.note})
+			if err := s.HandleTaskCommand(append([]string{"add"}, tt.args...)); err != nil {
+				t.Fatalf("task add: %v", err)
+			}
+			if got := readNote(t, s, "projects/p.md"); got != tt.want {
+				t.Errorf("note =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskDone(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/p.md": lines("- [ ] Review the PR #code", "- [ ] Deploy", "- [x] Plan"),
+	})
+	if err := s.HandleTaskCommand([]string{"done", "review"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+	want := lines("- [x] Review the PR #code", "- [ ] Deploy", "- [x] Plan")
+	if got := readNote(t, s, "projects/p.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := s.HandleTaskCommand([]string{"done", "plan"}); err == nil {
+		t.Errorf("task done on a finished task: want an error")
+	}
+}
+
+func TestTaskEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "text keeps tokens, tags and id",
+			args: []string{"draft", "--text", "Write the launch post"},
+			want: "- [ ] Write the launch post due:2024-05-01 est:2h #blog ^t-1a2b",
+		},
+		{
+			name: "tags",
+			args: []string{"draft", "--tag", "launch", "--untag", "blog"},
+			want: "- [ ] Draft post due:2024-05-01 est:2h #launch ^t-1a2b",
+		},
+		{
+			name: "clear due and change estimate",
+			args: []string{"draft", "--due", "none", "--est", "30m"},
+			want: "- [ ] Draft post #blog est:30m ^t-1a2b",
+		},
+		{
+			name: "reschedule",
+			args: []string{"^t-1a2b", "--due", "2024-06-01"},
+			want: "- [ ] Draft post est:2h #blog due:2024-06-01 ^t-1a2b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestVault(t, map[string]string{
+				"projects/p.md": lines("- [ ] Draft post due:2024-05-01 est:2h #blog ^t-1a2b", "  Notes below"),
+			})
+			if err := s.HandleTaskCommand(append([]string{"edit"}, tt.args...)); err != nil {
+				t.Fatalf("task edit: %v", err)
+			}
+			want := lines(tt.want, "  Notes below")
+			if got := readNote(t, s, "projects/p.md"); got != want {
+				t.Errorf("note =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestTaskDue(t *testing.T) {
+	s := newTestVault(t, map[string]string{"projects/p.md": lines("- [ ] Renew passport due:2024-01-01")})
+	if err := s.HandleTaskCommand([]string{"due", "passport", "2024-02-01"}); err != nil {
+		t.Fatalf("task due: %v", err)
+	}
+	want := lines("- [ ] Renew passport due:2024-02-01")
+	if got := readNote(t, s, "projects/p.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTaskAmbiguousMatch(t *testing.T) {
+	note := lines("- [ ] Review the PR", "- [ ] Review the PR again", "- [ ] Review docs")
+	s := newTestVault(t, map[string]string{"projects/p.md": note})
+
+	for _, args := range [][]string{
+		{"done", "review"},
+		{"due", "review", "2024-02-01"},
+		{"edit", "review", "--tag", "x"},
+	} {
+		err := s.HandleTaskCommand(args)
+		if err == nil || !strings.Contains(err.Error(), "projects/p.md:L3 Review docs") {
+			t.Errorf("task %v = %v, want an error listing the candidates", args, err)
+		}
+	}
+	if got := readNote(t, s, "projects/p.md"); got != note {
+		t.Errorf("note changed:\n%s", got)
+	}
+
+	// The whole text of one task picks it
+	if err := s.HandleTaskCommand([]string{"done", "review the pr"}); err != nil {
+		t.Fatalf("task done: %v", err)
+	}
+	want := lines("- [x] Review the PR", "- [ ] Review the PR again", "- [ ] Review docs")
+	if got := readNote(t, s, "projects/p.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTaskAddTarget(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/p.md":      lines("# Project"),
+		"projects/data.csv":  "a,b\n",
+		".notes/config.yaml": "git:\n  auto_commit: false\n",
+		"private/secret.md":  lines("# Secret"),
+	})
+	for _, target := range []string{".notes/config.yaml", "projects/data.csv", "private/secret.md", "private/secret", "../outside.md"} {
+		if err := s.HandleTaskCommand([]string{"add", "Leak", "--to", target}); err == nil {
+			t.Errorf("task add --to %s: want an error", target)
+		}
+	}
+	if got := readNote(t, s, ".notes/config.yaml"); got != "git:\n  auto_commit: false\n" {
+		t.Errorf("config changed:\n%s", got)
+	}
+	if got := readNote(t, s, "private/secret.md"); got != lines("# Secret") {
+		t.Errorf("note outside the note folders changed:\n%s", got)
+	}
+}
+
+func TestTaskEditKeepsAssignees(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "Pair on the migration", want: "- [ ] Pair on the migration @alice #db @bob-jones ^t-1a2b"},
+		{text: "Pair with @bob-jones", want: "- [ ] Pair with @bob-jones @alice #db ^t-1a2b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			s := newTestVault(t, map[string]string{
+				"projects/p.md": lines("- [ ] Pair on it @alice #db @bob-jones ^t-1a2b"),
+			})
+			if err := s.HandleTaskCommand([]string{"edit", "^t-1a2b", "--text", tt.text}); err != nil {
+				t.Fatalf("task edit: %v", err)
+			}
+			if got := readNote(t, s, "projects/p.md"); got != lines(tt.want) {
+				t.Errorf("note =\n%s\nwant\n%s", got, lines(tt.want))
+			}
+		})
+	}
+}
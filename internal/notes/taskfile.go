@@ -0,0 +1,163 @@
+package notes
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"notes/internal/frontmatter"
+)
+
+// taskLinePattern matches a markdown task in any status, capturing its
+// indent, the checkbox mark and the text
+var taskLinePattern = regexp.MustCompile(`^(\s*)-\s*\[\s*([xX/>-]?)\s*\]\s*(.*)$`)
+
+// taskIDPattern matches a block anchor such as ^t-3f9a01c2 at the end of a task
+var taskIDPattern = regexp.MustCompile(`\s\^([A-Za-z0-9][\w-]*)\s*$`)
+
+// readLines reads a file as lines, keeping a trailing empty line if the
+// file ends with a newline so writeLines round-trips it
+func readLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(content), "\n"), nil
+}
+
+func writeLines(path string, lines []string) error {
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// noteTextLines reports for each line of a note whether it is note text
+// rather than frontmatter or part of a fenced code block. Tasks are only
+// read from note text, so rewrites of task lines should skip the rest.
+func noteTextLines(lines []string) []bool {
+	fm, _, _ := frontmatter.Parse([]byte(strings.Join(lines, "\n")))
+	skipLines := 0
+	if fm != nil {
+		skipLines = fm.Lines
+	}
+
+	text := make([]bool, len(lines))
+	inFence := false
+	for i, line := range lines {
+		switch {
+		case i < skipLines:
+		case codeFencePattern.MatchString(line):
+			inFence = !inFence
+		default:
+			text[i] = !inFence
+		}
+	}
+	return text
+}
+
+// indentOf returns the number of leading spaces and tabs on a line
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// locateTask finds a task in a file by its stable ID, falling back to an
+// exact text match. It refuses to guess when the task is missing or the
+// text matches more than one task.
+func (s *Service) locateTask(filePath, id, text string) (*TaskInfo, error) {
+	tasks := s.extractTasks(filePath)
+	relPath, _ := filepath.Rel(s.config.BaseDir, filePath)
+
+	if id != "" {
+		var idMatches []TaskInfo
+		for _, task := range tasks {
+			if task.ID == id {
+				idMatches = append(idMatches, task)
+			}
+		}
+		switch len(idMatches) {
+		case 0:
+		case 1:
+			return &idMatches[0], nil
+		default:
+			return nil, fmt.Errorf("ambiguous ID ^%s: %d tasks in %s have it", id, len(idMatches), relPath)
+		}
+	}
+
+	var matches []TaskInfo
+	for _, task := range tasks {
+		if task.Text == text {
+			matches = append(matches, task)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return &matches[0], nil
+	case 0:
+		if id != "" {
+			return nil, fmt.Errorf("task ^%s (%q) is no longer in %s", id, text, relPath)
+		}
+		return nil, fmt.Errorf("task %q is no longer in %s", text, relPath)
+	default:
+		return nil, fmt.Errorf("task %q appears %d times in %s; add a ^id to tell them apart", text, len(matches), relPath)
+	}
+}
+
+// ensureTaskID gives a task a stable block ID if it does not have one yet,
+// writing it to the end of the task line. It returns the task's ID.
+func (s *Service) ensureTaskID(task *TaskInfo) (string, error) {
+	if task.ID != "" {
+		return task.ID, nil
+	}
+
+	lines, err := readLines(task.FilePath)
+	if err != nil {
+		return "", err
+	}
+	index := task.Line - 1
+	if index < 0 || index >= len(lines) || !taskLinePattern.MatchString(lines[index]) {
+		return "", fmt.Errorf("task line %d changed before an ID could be added", task.Line)
+	}
+
+	// IDs are looked up across the whole vault, so they must be unique in it
+	used := make(map[string]bool)
+	s.walkNotes(func(path string) {
+		for _, other := range s.extractTasks(path) {
+			used[other.ID] = true
+		}
+	})
+
+	id := ""
+	for attempt := 0; attempt < 100; attempt++ {
+		candidate, err := newTaskID()
+		if err != nil {
+			return "", err
+		}
+		if !used[candidate] {
+			id = candidate
+			break
+		}
+	}
+	if id == "" {
+		return "", fmt.Errorf("could not generate a unique task ID")
+	}
+
+	lines[index] = strings.TrimRight(lines[index], " \t") + " ^" + id
+	if err := writeLines(task.FilePath, lines); err != nil {
+		return "", err
+	}
+
+	task.ID = id
+	return id, nil
+}
+
+// newTaskID returns a random ID such as t-3f9a01c2
+func newTaskID() (string, error) {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate task ID: %w", err)
+	}
+	return "t-" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,303 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// findTimeEntry resolves the "<task> <n>" arguments of the time entry
+// commands to a task and the index of one of its entries, counting from 1
+// as notes time entries shows them
+func (s *Service) findTimeEntry(words []string) (*TaskInfo, int, error) {
+	if len(words) < 2 {
+		return nil, 0, fmt.Errorf("give a task and an entry number (see notes time entries <task>)")
+	}
+	n, err := strconv.Atoi(words[len(words)-1])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid entry number %q (see notes time entries <task>)", words[len(words)-1])
+	}
+
+	task, err := s.findAnyTask(strings.Join(words[:len(words)-1], " "))
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not find task: %w", err)
+	}
+	switch {
+	case len(task.TimeEntries) == 0:
+		return nil, 0, fmt.Errorf("no time has been logged on %q", task.Text)
+	case n < 1 || n > len(task.TimeEntries):
+		return nil, 0, fmt.Errorf("%q has entries 1-%d, not %d", task.Text, len(task.TimeEntries), n)
+	}
+	return task, n - 1, nil
+}
+
+// replaceTimeEntry swaps an entry's log line for the given entries (none
+// to delete it) and updates the task's summary line
+func (s *Service) replaceTimeEntry(task *TaskInfo, entry TimeEntry, replacements ...TimeEntry) error {
+	lines, err := readLines(task.FilePath)
+	if err != nil {
+		return err
+	}
+
+	index := entry.Line - 1
+	if index < 0 || index >= len(lines) {
+		return fmt.Errorf("time entry line %d not found in file", entry.Line)
+	}
+	if current, err := parseTimeEntry(lines[index]); err != nil || formatTimeEntry(*current) != formatTimeEntry(entry) {
+		return fmt.Errorf("time entry line %d changed; run the command again", entry.Line)
+	}
+
+	newLines := make([]string, 0, len(lines)+len(replacements))
+	newLines = append(newLines, lines[:index]...)
+	for _, replacement := range replacements {
+		newLines = append(newLines, formatTimeEntry(replacement))
+	}
+	newLines = append(newLines, lines[index+1:]...)
+	if err := writeLines(task.FilePath, newLines); err != nil {
+		return err
+	}
+	return s.syncTimeSummary(task.FilePath, task.Line)
+}
+
+// withRange returns entry moved to start-end, with a matching duration
+func withRange(entry TimeEntry, start, end time.Time) TimeEntry {
+	entry.Date = startOfDay(start)
+	entry.StartTime = start
+	entry.EndTime = end
+	entry.Duration = end.Sub(start)
+	return entry
+}
+
+// listTimeEntries prints a task's time log with the numbers the other
+// entry commands take
+func (s *Service) listTimeEntries(words []string) error {
+	if len(words) == 0 {
+		return fmt.Errorf("usage: notes time entries <task>")
+	}
+	task, err := s.findAnyTask(strings.Join(words, " "))
+	if err != nil {
+		return fmt.Errorf("could not find task: %w", err)
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+	fmt.Printf("\033[1;36m⏱  Time entries: %s\033[0m\n", task.Text)
+	fmt.Printf("\033[90m%s:L%d\033[0m\n", relPath, task.Line)
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+
+	if len(task.TimeEntries) == 0 {
+		fmt.Printf("\033[90mNo time logged yet.\033[0m\n")
+		return nil
+	}
+
+	var total time.Duration
+	for i, entry := range task.TimeEntries {
+		total += entry.Duration
+		warning := ""
+		if entry.EndTime.Sub(entry.StartTime).Truncate(time.Minute) != entry.Duration.Truncate(time.Minute) {
+			warning = fmt.Sprintf(" \033[1;33m⚠ range is %s\033[0m", formatDuration(entry.EndTime.Sub(entry.StartTime)))
+		}
+		fmt.Printf("\033[1m[%d]\033[0m %s \033[33m(%s)\033[0m %s%s\n", i+1,
+			formatEntryRange(entry), formatDuration(entry.Duration), entry.Description, warning)
+	}
+	fmt.Printf("\033[90m" + strings.Repeat("─", 50) + "\033[0m\n")
+	entries := "entries"
+	if len(task.TimeEntries) == 1 {
+		entries = "entry"
+	}
+	fmt.Printf("\033[1mTotal: %s\033[0m in %d %s\n", formatDuration(total), len(task.TimeEntries), entries)
+	return nil
+}
+
+// editTimeEntry changes the day, start, end or description of an entry,
+// keeping its duration in line with its range:
+//
+//	notes time edit <task> <n> [--date day] [--start HH:MM] [--end [day] HH:MM] [-m message]
+func (s *Service) editTimeEntry(args []string) error {
+	flags, words, err := parseEntryFlags(args, "--date", "--start", "--end", "-m")
+	if err != nil {
+		return err
+	}
+	if len(flags) == 0 {
+		return fmt.Errorf("nothing to change; use --date, --start, --end or -m")
+	}
+	task, n, err := s.findTimeEntry(words)
+	if err != nil {
+		return err
+	}
+	entry := task.TimeEntries[n]
+	now := time.Now()
+
+	// Moving an entry to another day keeps its times of day
+	start, end := entry.StartTime, entry.EndTime
+	if value, ok := flags["--date"]; ok {
+		date, err := parseDay(strings.ToLower(value), now)
+		if err != nil {
+			return fmt.Errorf("invalid date %q (use YYYY-MM-DD, today, yesterday or -2d)", value)
+		}
+		days := daysUntil(date, start)
+		start, end = start.AddDate(0, 0, days), end.AddDate(0, 0, days)
+	}
+	if value, ok := flags["--start"]; ok {
+		if start, ok = clockOn(startOfDay(start), value); !ok {
+			return fmt.Errorf("invalid time %q for --start (use HH:MM or 9am)", value)
+		}
+	}
+	if value, ok := flags["--end"]; ok {
+		dated := false
+		if end, dated, ok = parseEntryEnd(startOfDay(start), value, now); !ok {
+			return fmt.Errorf("invalid time %q for --end (use HH:MM, or YYYY-MM-DD HH:MM for another day)", value)
+		}
+		// Running past midnight has to be asked for, not guessed from a typo
+		if !dated && end.Before(start) {
+			return fmt.Errorf("--end %s is before the start (%s); to end the next day use --end \"%s %s\"",
+				value, start.Format("15:04"), start.AddDate(0, 0, 1).Format("2006-01-02"), value)
+		}
+	}
+	if err := checkEntryRange(start, end, now); err != nil {
+		return err
+	}
+
+	edited := withRange(entry, start, end)
+	if value, ok := flags["-m"]; ok {
+		edited.Description = value
+	}
+	if overlap := s.findOverlap(start, end, task.FilePath, entry.Line); overlap != "" {
+		return fmt.Errorf("%s overlaps %s", formatEntryRange(edited), overlap)
+	}
+	// Like new entries, an entry past midnight is kept as one per day
+	parts := splitAtMidnight(edited)
+	if err := s.replaceTimeEntry(task, entry, parts...); err != nil {
+		return err
+	}
+
+	fmt.Printf("✏️  Edited entry %d on: \033[1m%s\033[0m\n", n+1, task.Text)
+	fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(formatTimeEntry(entry)))
+	for _, part := range parts {
+		fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(formatTimeEntry(part)))
+	}
+	return nil
+}
+
+// parseEntryEnd reads an --end value: a time of day on date, or a day and
+// a time such as "2024-01-16 00:45" for an entry that ends on another day.
+// dated reports whether a day was given.
+func parseEntryEnd(date time.Time, value string, now time.Time) (end time.Time, dated, ok bool) {
+	fields := strings.Fields(value)
+	switch len(fields) {
+	case 1:
+		end, ok = clockOn(date, fields[0])
+		return end, false, ok
+	case 2:
+		day, err := parseDay(strings.ToLower(fields[0]), now)
+		if err != nil {
+			return end, true, false
+		}
+		end, ok = clockOn(day, fields[1])
+		return end, true, ok
+	}
+	return end, false, false
+}
+
+// splitTimeEntry cuts an entry in two at a time of day. The second half
+// gets the -m description if one is given:
+//
+//	notes time split <task> <n> <HH:MM> [-m message]
+func (s *Service) splitTimeEntry(args []string) error {
+	flags, words, err := parseEntryFlags(args, "-m")
+	if err != nil {
+		return err
+	}
+	if len(words) < 3 {
+		return fmt.Errorf("usage: notes time split <task> <n> <HH:MM> [-m message]")
+	}
+	at := words[len(words)-1]
+	task, n, err := s.findTimeEntry(words[:len(words)-1])
+	if err != nil {
+		return err
+	}
+	entry := task.TimeEntries[n]
+
+	split, ok := clockOn(startOfDay(entry.StartTime), at)
+	if !ok {
+		return fmt.Errorf("invalid time %q (use HH:MM or 9am)", at)
+	}
+	// In an entry past midnight, an earlier time of day is the next day
+	if split.Before(entry.StartTime) {
+		split = split.AddDate(0, 0, 1)
+	}
+	if !split.After(entry.StartTime) || !split.Before(entry.EndTime) {
+		return fmt.Errorf("%s is not inside the entry (%s)", at, formatEntryRange(entry))
+	}
+
+	first := withRange(entry, entry.StartTime, split)
+	second := withRange(entry, split, entry.EndTime)
+	if value, ok := flags["-m"]; ok {
+		second.Description = value
+	}
+	if err := s.replaceTimeEntry(task, entry, first, second); err != nil {
+		return err
+	}
+
+	fmt.Printf("✂️  Split entry %d on: \033[1m%s\033[0m\n", n+1, task.Text)
+	fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(formatTimeEntry(entry)))
+	fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(formatTimeEntry(first)))
+	fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(formatTimeEntry(second)))
+	return nil
+}
+
+// removeTimeEntry deletes an entry from a task's time log:
+//
+//	notes time rm <task> <n>
+func (s *Service) removeTimeEntry(args []string) error {
+	_, words, err := parseEntryFlags(args)
+	if err != nil {
+		return err
+	}
+	task, n, err := s.findTimeEntry(words)
+	if err != nil {
+		return err
+	}
+	entry := task.TimeEntries[n]
+	if err := s.replaceTimeEntry(task, entry); err != nil {
+		return err
+	}
+
+	fmt.Printf("🗑  Removed entry %d from: \033[1m%s\033[0m\n", n+1, task.Text)
+	fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(formatTimeEntry(entry)))
+	return nil
+}
+
+// parseEntryFlags splits the arguments of a time entry command into the
+// allowed flags, each taking a value, and the remaining words
+func parseEntryFlags(args []string, allowed ...string) (map[string]string, []string, error) {
+	flags := make(map[string]string)
+	var words []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--message" {
+			arg = "-m"
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			words = append(words, arg)
+			continue
+		}
+		known := false
+		for _, flag := range allowed {
+			known = known || flag == arg
+		}
+		if !known {
+			return nil, nil, fmt.Errorf("unknown flag: %s", args[i])
+		}
+		if i+1 >= len(args) {
+			return nil, nil, fmt.Errorf("%s requires a value", args[i])
+		}
+		i++
+		flags[arg] = strings.TrimSpace(args[i])
+	}
+	if message, ok := flags["-m"]; ok && message == "" {
+		return nil, nil, fmt.Errorf("the -m message can't be empty")
+	}
+	return flags, words, nil
+}
@@ -0,0 +1,44 @@
+package notes
+
+import (
+	"testing"
+)
+
+func TestRemoveTimeEntry(t *testing.T) {
+	note := lines(
+		"- [x] Write the spec est:2h",
+		"  Time log:",
+		"  • 2024-01-14 09:00-10:00 (1h) - Draft",
+		"  • 2024-01-15 09:00-09:30 (30m) - Review",
+		"  Total: 1h30m (under estimate by 30m)",
+		"- [ ] Next task")
+
+	s := newTestVault(t, map[string]string{"projects/spec.md": note})
+	if err := s.HandleTimeCommand([]string{"entries", "write the spec"}); err != nil {
+		t.Fatalf("time entries on a done task: %v", err)
+	}
+
+	if err := s.HandleTimeCommand([]string{"rm", "write the spec", "2"}); err != nil {
+		t.Fatalf("time rm on a done task: %v", err)
+	}
+	want := lines(
+		"- [x] Write the spec est:2h",
+		"  Time log:",
+		"  • 2024-01-14 09:00-10:00 (1h) - Draft",
+		"  Total: 1h (under estimate by 1h)",
+		"- [ ] Next task")
+	if got := readNote(t, s, "projects/spec.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+
+	// Removing the last entry removes the whole time log
+	if err := s.HandleTimeCommand([]string{"rm", "write the spec", "1"}); err != nil {
+		t.Fatalf("time rm: %v", err)
+	}
+	want = lines(
+		"- [x] Write the spec est:2h",
+		"- [ ] Next task")
+	if got := readNote(t, s, "projects/spec.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
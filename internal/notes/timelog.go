@@ -0,0 +1,479 @@
+package notes
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultSessionDescription describes time logged without a description
+const defaultSessionDescription = "Work session"
+
+var (
+	// timeSummaryPattern matches the Remaining: or Total: line that closes a
+	// task's time log
+	timeSummaryPattern = regexp.MustCompile(`^\s*(Remaining|Total):`)
+	// timeEntryLinePattern matches an entry in a time log, bulleted with •
+	// as notes writes them or with * or - when written by hand
+	timeEntryLinePattern = regexp.MustCompile(`^\s*[•*-]\s*\d{4}-\d{2}-\d{2}\s`)
+)
+
+// timeSummary returns the summary line for a task's time log. Open tasks
+// with an estimate show the time left, anything else the total logged,
+// and finished tasks compare that total with their estimate.
+func timeSummary(task TaskInfo) string {
+	var logged time.Duration
+	for _, entry := range task.TimeEntries {
+		logged += entry.Duration
+	}
+	estimate, err := parseDuration(task.Estimate)
+	hasEstimate := task.Estimate != "" && err == nil && estimate > 0
+
+	if task.Status.IsOpen() {
+		switch {
+		case !hasEstimate:
+			return "Total: " + formatDuration(logged)
+		case logged < estimate:
+			return "Remaining: ~" + formatDuration(estimate-logged)
+		case logged == estimate:
+			return "Remaining: 0m"
+		default:
+			return fmt.Sprintf("Remaining: 0m (over estimate by %s)", formatDuration(logged-estimate))
+		}
+	}
+
+	total := "Total: " + formatDuration(logged)
+	switch {
+	case !hasEstimate:
+		return total
+	case logged > estimate:
+		return fmt.Sprintf("%s (over estimate by %s)", total, formatDuration(logged-estimate))
+	case logged < estimate:
+		return fmt.Sprintf("%s (under estimate by %s)", total, formatDuration(estimate-logged))
+	default:
+		return total + " (on estimate!)"
+	}
+}
+
+// updateTimeSummary rewrites the Remaining: or Total: line below a task's
+// time log in lines, inserting it after the last entry if it is missing.
+// A time log left without entries is removed, summary and all. It returns
+// the updated lines, the lines it replaced or removed and the new summary
+// line, if any; after is nil when nothing changed. Tasks without a time log
+// are left alone.
+func updateTimeSummary(lines []string, task TaskInfo) (updated, before, after []string) {
+	index := task.Line - 1
+	if index < 0 || index >= len(lines) {
+		return lines, nil, nil
+	}
+
+	timeLog, lastEntry := -1, -1
+	removed := make(map[int]bool)
+	for i := index + 1; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Stop at a blank line, outdented content or a subtask
+		if trimmed == "" || indentOf(line) <= task.Indent || taskLinePattern.MatchString(line) {
+			break
+		}
+
+		switch {
+		case trimmed == "Time log:":
+			timeLog, lastEntry = i, i
+		case timeLog >= 0 && timeEntryLinePattern.MatchString(line):
+			lastEntry = i
+		case timeSummaryPattern.MatchString(line):
+			removed[i] = true
+			before = append(before, line)
+		}
+	}
+	if timeLog < 0 {
+		return lines, nil, nil
+	}
+
+	if len(task.TimeEntries) == 0 {
+		// Entry lines that don't parse are left for the user to fix
+		if lastEntry != timeLog {
+			return lines, nil, nil
+		}
+		removed[timeLog] = true
+		before = append([]string{lines[timeLog]}, before...)
+		after = []string{}
+	} else {
+		indent := lines[timeLog][:indentOf(lines[timeLog])]
+		after = []string{indent + timeSummary(task)}
+	}
+
+	updated = make([]string, 0, len(lines)+1)
+	for i, line := range lines {
+		if !removed[i] {
+			updated = append(updated, line)
+		}
+		if i == lastEntry {
+			updated = append(updated, after...)
+		}
+	}
+	if strings.Join(updated, "\n") == strings.Join(lines, "\n") {
+		return lines, nil, nil
+	}
+	return updated, before, after
+}
+
+// syncTimeSummary brings the summary line of the task at line up to date
+// with its time log and status
+func (s *Service) syncTimeSummary(filePath string, line int) error {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range s.extractTasks(filePath) {
+		if task.Line != line {
+			continue
+		}
+		updated, _, after := updateTimeSummary(lines, task)
+		if after == nil {
+			return nil
+		}
+		return writeLines(filePath, updated)
+	}
+	return fmt.Errorf("task line %d not found in file", line)
+}
+
+// RecalcTimeSummaries rewrites the Remaining: and Total: lines of every
+// task with a time log in the vault, repairing ones that have drifted from
+// the logged entries
+func (s *Service) RecalcTimeSummaries(dryRun bool) error {
+	changed := 0
+
+	s.walkNotes(func(path string) {
+		lines, err := readLines(path)
+		if err != nil {
+			return
+		}
+
+		relPath, _ := filepath.Rel(s.config.BaseDir, path)
+		// Earlier rewrites can add or remove lines, shifting later tasks
+		offset := 0
+		fileChanged := false
+		for _, task := range s.extractTasks(path) {
+			task.Line += offset
+			updated, before, after := updateTimeSummary(lines, task)
+			if after == nil {
+				continue
+			}
+
+			fmt.Printf("\033[1;34m%s:L%d\033[0m %s\n", relPath, task.Line-offset, task.Text)
+			for _, line := range before {
+				fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(line))
+			}
+			for _, line := range after {
+				fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(line))
+			}
+			offset += len(updated) - len(lines)
+			lines = updated
+			fileChanged = true
+			changed++
+		}
+
+		if fileChanged && !dryRun {
+			if err := writeLines(path, lines); err != nil {
+				fmt.Printf("\033[1;31m✗ %s: %v\033[0m\n", relPath, err)
+			}
+		}
+	})
+
+	switch {
+	case changed == 0:
+		fmt.Printf("✅ Time summaries are up to date\n")
+	case dryRun:
+		fmt.Printf("\n\033[90m%d task%s would be updated (dry run)\033[0m\n", changed, pluralize(changed))
+	default:
+		fmt.Printf("\n✅ Updated time summaries on %d task%s\n", changed, pluralize(changed))
+	}
+	return nil
+}
+
+// logTimeEntry writes an entry into a task's time log, one entry per day
+// if it runs past midnight, and updates the summary line below it
+func (s *Service) logTimeEntry(task *TaskInfo, entry TimeEntry) error {
+	for _, part := range splitAtMidnight(entry) {
+		if err := s.insertTimeEntry(task, part); err != nil {
+			return err
+		}
+	}
+	return s.syncTimeSummary(task.FilePath, task.Line)
+}
+
+// splitAtMidnight cuts an entry that runs past midnight into one entry per
+// day, sharing its duration out in proportion to the time on each day. The
+// shares are whole minutes, or seconds if the entry has them, and add up to
+// the entry's duration. An entry within one day is returned as it is.
+func splitAtMidnight(entry TimeEntry) []TimeEntry {
+	span := entry.EndTime.Sub(entry.StartTime)
+	if !entry.EndTime.After(startOfDay(entry.StartTime).AddDate(0, 0, 1)) {
+		return []TimeEntry{entry}
+	}
+	unit := time.Minute
+	if entry.Seconds || entry.Duration%time.Minute != 0 {
+		unit = time.Second
+	}
+
+	var parts []TimeEntry
+	var shared time.Duration
+	for start := entry.StartTime; start.Before(entry.EndTime); {
+		end := startOfDay(start).AddDate(0, 0, 1)
+		if end.After(entry.EndTime) {
+			end = entry.EndTime
+		}
+		// Rounding the running total rather than each share keeps the
+		// rounding from adding up
+		total := entry.Duration
+		if end.Before(entry.EndTime) {
+			total = time.Duration(float64(entry.Duration) * float64(end.Sub(entry.StartTime)) / float64(span)).Round(unit)
+		}
+
+		part := entry
+		part.Date = startOfDay(start)
+		part.StartTime = start
+		part.EndTime = end
+		part.Duration = total - shared
+		shared = total
+		parts = append(parts, part)
+		start = end
+	}
+	return parts
+}
+
+// parseManualEntry works out when manually logged time started and ended.
+// spec is a duration, which ends at --at plus the duration or else now, or
+// a clock range such as 14:00-15:30. day is the --date value.
+func parseManualEntry(spec, at, day string, now time.Time) (start, end time.Time, err error) {
+	date := startOfDay(now)
+	if day != "" {
+		if date, err = parseDay(strings.ToLower(day), now); err != nil {
+			return start, end, fmt.Errorf("invalid date %q (use YYYY-MM-DD, today, yesterday or -2d)", day)
+		}
+	}
+	if from, to, found := strings.Cut(spec, "-"); found {
+		if at != "" {
+			return start, end, fmt.Errorf("--at can't be combined with a time range")
+		}
+		var okFrom, okTo bool
+		start, okFrom = clockOn(date, from)
+		end, okTo = clockOn(date, to)
+		if !okFrom || !okTo {
+			return start, end, fmt.Errorf("invalid time range %q (use HH:MM-HH:MM)", spec)
+		}
+		// 23:30-00:45 ends the next day
+		if end.Before(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+	} else {
+		duration, err := parseDuration(spec)
+		if err != nil || duration <= 0 {
+			return start, end, fmt.Errorf("invalid duration %q (use e.g. 45m, 1h30m or 14:00-15:30)", spec)
+		}
+		duration = duration.Truncate(time.Minute)
+		switch {
+		case at != "":
+			var ok bool
+			if start, ok = clockOn(date, at); !ok {
+				return start, end, fmt.Errorf("invalid time %q for --at (use HH:MM or 9am)", at)
+			}
+			end = start.Add(duration)
+		case day == "" || date.Equal(startOfDay(now)):
+			end = now.Truncate(time.Minute)
+			start = end.Add(-duration)
+		default:
+			return start, end, fmt.Errorf("--at is required when logging time on another day")
+		}
+	}
+
+	return start, end, checkEntryRange(start, end, now)
+}
+
+// clockOn returns a time of day such as 14:00 or 9am on date
+func clockOn(date time.Time, value string) (time.Time, bool) {
+	hour, minute, ok := parseClock(strings.ToLower(value))
+	return date.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute), ok
+}
+
+// checkEntryRange checks that a time entry can be written to a time log
+func checkEntryRange(start, end, now time.Time) error {
+	switch {
+	case !end.After(start):
+		return fmt.Errorf("the entry must end after it starts")
+	case end.After(now):
+		return fmt.Errorf("can't log time that hasn't happened yet (ends %s)", end.Format("2006-01-02 15:04"))
+	}
+	return nil
+}
+
+// findOverlap returns a description of logged time, or the running timer,
+// that overlaps start-end, or "" if that time is free. The entry at
+// skipLine of skipFile, one being edited, doesn't count.
+func (s *Service) findOverlap(start, end time.Time, skipFile string, skipLine int) string {
+	if state, err := s.loadTimerState(); err == nil && state.IsActive && state.StartTime.Before(end) {
+		return fmt.Sprintf("the running timer for %q (started %s)", state.TaskText, state.StartTime.Format("2006-01-02 15:04"))
+	}
+
+	overlap := ""
+	s.walkNotes(func(path string) {
+		if overlap != "" {
+			return
+		}
+		for _, task := range s.extractTasks(path) {
+			for _, entry := range task.TimeEntries {
+				// Sessions shorter than a minute are logged as 14:24-14:24
+				if !entry.EndTime.After(entry.StartTime) || path == skipFile && entry.Line == skipLine {
+					continue
+				}
+				if entry.StartTime.Before(end) && start.Before(entry.EndTime) {
+					relPath, _ := filepath.Rel(s.config.BaseDir, path)
+					overlap = fmt.Sprintf("%s on %q (%s:L%d)", formatEntryRange(entry), task.Text, relPath, task.Line)
+					return
+				}
+			}
+		}
+	})
+	return overlap
+}
+
+// logTime adds time worked without a timer to a task's time log:
+//
+//	notes time log <task> <duration|HH:MM-HH:MM> [--at HH:MM] [--date day] [-m message]
+func (s *Service) logTime(args []string) error {
+	var words []string
+	var at, day string
+	message := defaultSessionDescription
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--at", "--date", "-m", "--message":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			switch arg {
+			case "--at":
+				at = args[i]
+			case "--date":
+				day = args[i]
+			default:
+				message = strings.TrimSpace(args[i])
+			}
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return fmt.Errorf("unknown flag: %s", arg)
+			}
+			words = append(words, arg)
+		}
+	}
+	if len(words) < 2 {
+		return fmt.Errorf("usage: notes time log <task> <duration|HH:MM-HH:MM> [--at HH:MM] [--date YYYY-MM-DD] [-m message]")
+	}
+	if message == "" {
+		return fmt.Errorf("the -m message can't be empty")
+	}
+
+	start, end, err := parseManualEntry(words[len(words)-1], at, day, time.Now())
+	if err != nil {
+		return err
+	}
+
+	task, err := s.findTaskByText(strings.Join(words[:len(words)-1], " "))
+	if err != nil {
+		return fmt.Errorf("could not find task: %w", err)
+	}
+
+	entry := TimeEntry{
+		Date:        startOfDay(start),
+		StartTime:   start,
+		EndTime:     end,
+		Duration:    end.Sub(start),
+		Description: message,
+	}
+	if overlap := s.findOverlap(start, end, "", 0); overlap != "" {
+		return fmt.Errorf("%s overlaps %s", formatEntryRange(entry), overlap)
+	}
+	if err := s.logTimeEntry(task, entry); err != nil {
+		return fmt.Errorf("failed to add time entry: %w", err)
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, task.FilePath)
+	fmt.Printf("📝 Logged %s on: \033[1m%s\033[0m\n", formatDuration(entry.Duration), task.Text)
+	// Show the entries as written, one per day past midnight
+	for _, part := range splitAtMidnight(entry) {
+		fmt.Printf("  %s\n", strings.TrimSpace(formatTimeEntry(part)))
+	}
+	fmt.Printf("\033[90mLocation: %s:L%d\033[0m\n", relPath, task.Line)
+	return nil
+}
+
+// splitMessageFlag separates a -m (or --message) value from the other
+// arguments of a time command
+func splitMessageFlag(args []string) (rest []string, message string, err error) {
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "-m", "--message":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("%s requires a description", arg)
+			}
+			i++
+			message = strings.TrimSpace(args[i])
+		default:
+			if strings.HasPrefix(arg, "--") {
+				return nil, "", fmt.Errorf("unknown flag: %s", arg)
+			}
+			rest = append(rest, arg)
+		}
+	}
+	return rest, message, nil
+}
+
+// amendLastEntry replaces the description of the most recent time entry
+// in the vault
+func (s *Service) amendLastEntry(description string) error {
+	var last *TimeEntry
+	var lastTask TaskInfo
+	s.walkNotes(func(path string) {
+		for _, task := range s.extractTasks(path) {
+			for i, entry := range task.TimeEntries {
+				// Of entries started the same minute, the later one in the log wins
+				if last == nil || !entry.StartTime.Before(last.StartTime) {
+					last, lastTask = &task.TimeEntries[i], task
+				}
+			}
+		}
+	})
+	if last == nil {
+		return fmt.Errorf("no time has been logged yet")
+	}
+
+	lines, err := readLines(lastTask.FilePath)
+	if err != nil {
+		return err
+	}
+	index := last.Line - 1
+	if index < 0 || index >= len(lines) {
+		return fmt.Errorf("time entry line %d not found in file", last.Line)
+	}
+
+	before := lines[index]
+	amended := *last
+	amended.Description = description
+	lines[index] = formatTimeEntry(amended)
+	if err := writeLines(lastTask.FilePath, lines); err != nil {
+		return err
+	}
+
+	relPath, _ := filepath.Rel(s.config.BaseDir, lastTask.FilePath)
+	fmt.Printf("✏️  Amended last entry on: \033[1m%s\033[0m\n", lastTask.Text)
+	fmt.Printf("\033[90m%s:L%d\033[0m\n", relPath, last.Line)
+	fmt.Printf("  \033[31m- %s\033[0m\n", strings.TrimSpace(before))
+	fmt.Printf("  \033[32m+ %s\033[0m\n", strings.TrimSpace(lines[index]))
+	return nil
+}
@@ -0,0 +1,77 @@
+package notes
+
+import (
+	"testing"
+)
+
+func TestRecalcTimeSummaries(t *testing.T) {
+	note := lines(
+		"## Tasks",
+		"- [ ] Build the API est:2h",
+		"  Time log:",
+		"  • 2024-01-15 09:00-10:00 (1h) - Routes",
+		"  • 2024-01-15 11:00-11:30 (30m) - Tests",
+		"  Remaining: ~1h",
+		"- [x] Write the spec est:1h",
+		"  Time log:",
+		"  • 2024-01-14 09:00-10:30 (1h30m) - Draft",
+		"- [ ] Review est:1h",
+		"  Time log:",
+		"  • 2024-01-16 09:00-10:00 (1h) - Pass one",
+		"  Remaining: 0m",
+		"- [ ] Plan the sprint",
+		"  Some notes")
+
+	s := newTestVault(t, map[string]string{"projects/api.md": note})
+	if err := s.HandleTimeCommand([]string{"recalc", "--dry-run"}); err != nil {
+		t.Fatalf("time recalc --dry-run: %v", err)
+	}
+	if got := readNote(t, s, "projects/api.md"); got != note {
+		t.Errorf("dry run changed the note:\n%s", got)
+	}
+
+	if err := s.HandleTimeCommand([]string{"recalc"}); err != nil {
+		t.Fatalf("time recalc: %v", err)
+	}
+	want := lines(
+		"## Tasks",
+		"- [ ] Build the API est:2h",
+		"  Time log:",
+		"  • 2024-01-15 09:00-10:00 (1h) - Routes",
+		"  • 2024-01-15 11:00-11:30 (30m) - Tests",
+		"  Remaining: ~30m",
+		"- [x] Write the spec est:1h",
+		"  Time log:",
+		"  • 2024-01-14 09:00-10:30 (1h30m) - Draft",
+		"  Total: 1h30m (over estimate by 30m)",
+		"- [ ] Review est:1h",
+		"  Time log:",
+		"  • 2024-01-16 09:00-10:00 (1h) - Pass one",
+		"  Remaining: 0m",
+		"- [ ] Plan the sprint",
+		"  Some notes")
+	if got := readNote(t, s, "projects/api.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+
+	// A second run has nothing left to fix
+	if err := s.HandleTimeCommand([]string{"recalc"}); err != nil {
+		t.Fatalf("time recalc: %v", err)
+	}
+	if got := readNote(t, s, "projects/api.md"); got != want {
+		t.Errorf("second run changed the note:\n%s", got)
+	}
+}
+
+func TestRecalcRemovesEmptyTimeLog(t *testing.T) {
+	s := newTestVault(t, map[string]string{
+		"projects/api.md": lines("- [ ] Build the API", "  Time log:", "  Total: 1h", "  Some notes"),
+	})
+	if err := s.HandleTimeCommand([]string{"recalc"}); err != nil {
+		t.Fatalf("time recalc: %v", err)
+	}
+	want := lines("- [ ] Build the API", "  Some notes")
+	if got := readNote(t, s, "projects/api.md"); got != want {
+		t.Errorf("note =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -2,6 +2,9 @@ package notes
 
 import (
 	"fmt"
+	"strings"
+
+	"notes/internal/config"
 	"notes/internal/templates"
 )
 
@@ -15,16 +18,91 @@ const (
 	Learning = templates.Learning
 )
 
-func ValidateNoteType(n NoteType) error {
-	switch n {
-	case Daily, Project, Meeting, Design, Learning:
-		return nil
+// ValidateNoteType checks a note type against the types declared in the
+// vault config.
+func (s *Service) ValidateNoteType(n NoteType) error {
+	_, err := s.lookupNoteType(n)
+	return err
+}
+
+func (s *Service) lookupNoteType(n NoteType) (config.NoteTypeConfig, error) {
+	nt, ok := s.config.NoteType(string(n))
+	if !ok {
+		return nt, fmt.Errorf("invalid note type: %s. Available types: %s", n, strings.Join(s.config.NoteTypeNames(), ", "))
+	}
+	return nt, nil
+}
+
+// TaskStatus is the state of a task's checkbox
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in-progress"
+	StatusDone       TaskStatus = "done"
+	StatusCancelled  TaskStatus = "cancelled"
+	StatusDeferred   TaskStatus = "deferred"
+)
+
+// statusMarks maps the character between the brackets to a status
+var statusMarks = map[string]TaskStatus{
+	"":  StatusTodo,
+	"x": StatusDone,
+	"X": StatusDone,
+	"-": StatusCancelled,
+	"/": StatusInProgress,
+	">": StatusDeferred,
+}
+
+// Mark returns the checkbox written for a status, e.g. "[/]"
+func (st TaskStatus) Mark() string {
+	switch st {
+	case StatusDone:
+		return "[x]"
+	case StatusCancelled:
+		return "[-]"
+	case StatusInProgress:
+		return "[/]"
+	case StatusDeferred:
+		return "[>]"
 	default:
-		return fmt.Errorf("invalid note type: %s. Available types: daily, project, meeting, design, learning", n)
+		return "[ ]"
+	}
+}
+
+// IsOpen reports whether a task still needs doing
+func (st TaskStatus) IsOpen() bool {
+	return st == StatusTodo || st == StatusInProgress
+}
+
+// statusFilters lists the values accepted by notes tasks --status
+var statusFilters = []string{"open", "todo", "in-progress", "done", "cancelled", "deferred", "all"}
+
+// ValidateStatusFilter checks a --status value
+func ValidateStatusFilter(status string) error {
+	for _, name := range statusFilters {
+		if status == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid status: %s. Use one of: %s", status, strings.Join(statusFilters, ", "))
+}
+
+// matchesStatus reports whether a task status is selected by a --status
+// value. The empty filter means "open": todo and in-progress tasks.
+func matchesStatus(status TaskStatus, filter string) bool {
+	switch filter {
+	case "", "open":
+		return status.IsOpen()
+	case "all":
+		return true
+	default:
+		return string(status) == filter
 	}
 }
 
 type TaskFilters struct {
+	Status      string
 	Tags        []string
 	Priority    string
 	Overdue     bool
@@ -35,4 +113,32 @@ type TaskFilters struct {
 	All         bool
 	Summary     bool
 	Full        bool
-}
\ No newline at end of file
+	WithParents bool
+	Waiting     bool
+	Ready       bool
+	Mine        bool
+	Assignee    string
+	Upcoming    bool
+}
+
+// CreateOptions holds the optional inputs for notes create.
+type CreateOptions struct {
+	Vars   map[string]string
+	NoEdit bool
+}
+
+// SearchOptions holds the optional behavior for notes search.
+type SearchOptions struct {
+	Open bool
+}
+
+// ListOptions holds the filters for notes list.
+type ListOptions struct {
+	Where []string
+}
+
+// FindOptions holds the options for notes find and notes open.
+type FindOptions struct {
+	Print bool
+	Limit int
+}
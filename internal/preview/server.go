@@ -1,7 +1,10 @@
 package preview
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
 	_ "embed"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"io/ioutil"
@@ -10,9 +13,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/russross/blackfriday/v2"
+
+	"notes/internal/frontmatter"
 )
 
 //go:embed templates/index.html
@@ -24,6 +30,15 @@ var previewTemplate string
 type Server struct {
 	NotesDir string
 	Port     int
+
+	// ToggleTask, when set, lets checkboxes in the preview complete and
+	// reopen tasks. It receives the note's path and the task's line number.
+	ToggleTask func(filePath string, line int) error
+
+	// token is generated on each start and embedded in preview pages;
+	// edits must send it back, so only pages this server rendered can
+	// change notes
+	token string
 }
 
 type FolderGroup struct {
@@ -39,14 +54,22 @@ func NewServer(notesDir string, port int) *Server {
 }
 
 func (s *Server) Start() error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Errorf("failed to generate preview token: %w", err)
+	}
+	s.token = hex.EncodeToString(buf)
+
 	http.HandleFunc("/", s.handleIndex)
 	http.HandleFunc("/preview/", s.handlePreview)
+	http.HandleFunc("/toggle/", s.handleToggle)
 	http.HandleFunc("/static/", s.handleStatic)
 
 	fmt.Printf("Starting markdown preview server on http://localhost:%d\n", s.Port)
 	fmt.Printf("Serving notes from: %s\n", s.NotesDir)
 	
-	return http.ListenAndServe(fmt.Sprintf(":%d", s.Port), nil)
+	// Only this machine can reach the server, since it can edit notes
+	return http.ListenAndServe(fmt.Sprintf("127.0.0.1:%d", s.Port), nil)
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -79,22 +102,20 @@ func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Frontmatter is shown as a metadata card instead of raw text
+	fm, body, _ := frontmatter.Parse(content)
+	
 	// Configure blackfriday with extensions
 	extensions := blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs
-	html := blackfriday.Run(content, blackfriday.WithExtensions(extensions))
-	
-	// Convert markdown checkboxes to HTML checkboxes using regex
-	htmlStr := string(html)
-	
-	// Match unchecked boxes: <li>[ ] content</li> (including multiline and HTML content)
-	uncheckedRegex := regexp.MustCompile(`(?s)<li>(?:- )?\[ \]\s*(.*?)</li>`)
-	htmlStr = uncheckedRegex.ReplaceAllString(htmlStr, `<li><input type="checkbox" disabled> $1</li>`)
-	
-	// Match checked boxes: <li>[x] content</li> (including multiline and HTML content)
-	checkedRegex := regexp.MustCompile(`(?s)<li>(?:- )?\[x\]\s*(.*?)</li>`)
-	htmlStr = checkedRegex.ReplaceAllString(htmlStr, `<li><input type="checkbox" checked disabled> $1</li>`)
+	firstLine := 1
+	if fm != nil {
+		firstLine += fm.Lines
+	}
+	html := blackfriday.Run(markTaskLines(body, firstLine), blackfriday.WithExtensions(extensions))
 	
-	html = []byte(htmlStr)
+	// Convert markdown checkboxes to HTML checkboxes, carrying their
+	// source line so a click can be mapped back to the task
+	html = []byte(s.renderCheckboxes(string(html)))
 	
 	t, err := template.New("preview").Parse(previewTemplate)
 	if err != nil {
@@ -104,15 +125,131 @@ func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
 
 	data := struct {
 		Title   string
+		Meta    []frontmatter.Field
 		Content template.HTML
+		Token   string
 	}{
 		Title:   filename,
+		Meta:    fm.Display(),
 		Content: template.HTML(html),
+		Token:   s.token,
 	}
 
 	t.Execute(w, data)
 }
 
+var (
+	// sourceTaskRegex matches the checkbox of a task list item in markdown
+	sourceTaskRegex = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[[ xX/>-]\])`)
+	// sourceFenceRegex matches the opening or closing line of a code block
+	sourceFenceRegex = regexp.MustCompile("^\\s*(```|~~~)")
+	// lineMarkerRegex matches the line number markTaskLines adds after a
+	// checkbox, wrapped in private-use characters that rendering leaves be
+	lineMarkerRegex = regexp.MustCompile(`\x{E000}(\d+)\x{E000}`)
+	// checkboxRegex matches the start of a task list item; blackfriday
+	// escapes the deferred marker [>] as [&gt;]
+	checkboxRegex = regexp.MustCompile(`<li>(<p>)?(?:- )?\[( |x|X|/|-|&gt;)\](?:\x{E000}(\d+)\x{E000})?\s*`)
+)
+
+// markTaskLines tags each task checkbox outside code blocks with its line
+// number in the note, the first line of body being line first
+func markTaskLines(body []byte, first int) []byte {
+	lines := strings.Split(string(body), "\n")
+	inFence := false
+	for i, line := range lines {
+		if sourceFenceRegex.MatchString(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = sourceTaskRegex.ReplaceAllString(line, fmt.Sprintf("${1}\uE000%d\uE000", first+i))
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+func (s *Server) renderCheckboxes(htmlStr string) string {
+	htmlStr = checkboxRegex.ReplaceAllStringFunc(htmlStr, func(match string) string {
+		parts := checkboxRegex.FindStringSubmatch(match)
+		attrs := ""
+		if parts[3] != "" {
+			attrs = fmt.Sprintf(` data-line="%s"`, parts[3])
+		}
+
+		switch parts[2] {
+		case "x", "X":
+			attrs += " checked"
+		case "/":
+			attrs += ` class="in-progress" title="In progress"`
+		case "-":
+			attrs += ` class="cancelled" title="Cancelled" disabled`
+		case "&gt;":
+			attrs += ` class="deferred" title="Deferred" disabled`
+		}
+		if (s.ToggleTask == nil || parts[3] == "") && !strings.Contains(attrs, "disabled") {
+			attrs += " disabled"
+		}
+
+		return fmt.Sprintf(`<li>%s<input type="checkbox"%s> `, parts[1], attrs)
+	})
+	// Markers left over are in places no checkbox was rendered
+	return lineMarkerRegex.ReplaceAllString(htmlStr, "")
+}
+
+func (s *Server) handleToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.ToggleTask == nil {
+		http.Error(w, "Task editing is not enabled", http.StatusForbidden)
+		return
+	}
+	if !s.fromPreview(r) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	filename := strings.TrimPrefix(r.URL.Path, "/toggle/")
+	filePath := filepath.Join(s.NotesDir, filename)
+	if rel, err := filepath.Rel(s.NotesDir, filePath); err != nil || strings.HasPrefix(rel, "..") {
+		http.Error(w, "Invalid file", http.StatusBadRequest)
+		return
+	}
+
+	line, err := strconv.Atoi(r.URL.Query().Get("line"))
+	if err != nil {
+		http.Error(w, "Invalid task line", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.ToggleTask(filePath, line); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fromPreview reports whether a request was sent by one of this server's
+// own pages: addressed to the local server, from its origin, carrying the
+// token the page was rendered with. Other sites and other hosts can't edit
+// notes through it.
+func (s *Server) fromPreview(r *http.Request) bool {
+	local := false
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		local = local || r.Host == fmt.Sprintf("%s:%d", host, s.Port)
+	}
+	if !local {
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && origin != "http://"+r.Host {
+		return false
+	}
+	token := r.Header.Get("X-Notes-Token")
+	return s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
 func (s *Server) handleStatic(w http.ResponseWriter, r *http.Request) {
 	// This could serve static assets if needed in the future
 	http.NotFound(w, r)
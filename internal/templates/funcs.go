@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// slugPattern matches the runs of characters a slug leaves out: anything
+// but letters, digits and the accents combined with them, in any script
+var slugPattern = regexp.MustCompile(`[^\p{L}\p{M}\p{N}]+`)
+
+// Funcs returns the functions available inside note templates:
+//
+//	{{ dateAdd 1 }}              tomorrow's date (YYYY-MM-DD)
+//	{{ dateAdd -7 "Jan 2" }}     a week ago, with a Go layout
+//	{{ date "Monday, Jan 2" }}   today's date with a Go layout
+//	{{ week }}                   ISO week number
+//	{{ prevDaily }}              path of the previous daily note
+//	{{ user }}                   your name from config or git
+//	{{ slug .Title }}            kebab-case text
+//	{{ var "attendees" }}        a template variable, same as .Vars.attendees
+//	{{ quote .Title }}           a YAML-safe quoted string for frontmatter
+//	{{ list "a, b" }}            a YAML list from comma-separated text
+func Funcs(data TemplateData) template.FuncMap {
+	now := data.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	return template.FuncMap{
+		"dateAdd": func(days int, layout ...string) string {
+			return formatDate(now.AddDate(0, 0, days), layout)
+		},
+		"date": func(layout ...string) string {
+			return formatDate(now, layout)
+		},
+		"week": func() int {
+			_, week := now.ISOWeek()
+			return week
+		},
+		"prevDaily": func() string {
+			return data.PrevDaily
+		},
+		"user": func() string {
+			return data.User
+		},
+		"var": func(name string) string {
+			return data.Vars[name]
+		},
+		"quote": quote,
+		"list":  list,
+		"slug":  Slug,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// Slug converts text to lowercase kebab-case for file names and anchors.
+// Letters and digits of any script are kept, so "日本語 メモ" becomes
+// "日本語-メモ"; text without any gives an empty slug.
+func Slug(s string) string {
+	s = slugPattern.ReplaceAllString(s, "-")
+	s = strings.ToLower(s)
+	return strings.Trim(s, "-")
+}
+
+func formatDate(t time.Time, layout []string) string {
+	if len(layout) > 0 && layout[0] != "" {
+		return t.Format(layout[0])
+	}
+	return t.Format("2006-01-02")
+}
+
+// quote renders a string as a double-quoted scalar, which is valid YAML
+// whatever characters it contains.
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+// list renders comma-separated text as a YAML flow sequence.
+func list(s string) string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, quote(item))
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
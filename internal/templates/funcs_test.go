@@ -0,0 +1,24 @@
+package templates
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "API Redesign v2", want: "api-redesign-v2"},
+		{text: "  Q3: planning & review!  ", want: "q3-planning-review"},
+		{text: "Überprüfung der Zahlen", want: "überprüfung-der-zahlen"},
+		{text: "日本語", want: "日本語"},
+		{text: "日本語 メモ", want: "日本語-メモ"},
+		{text: "Café (draft)", want: "café-draft"},
+		{text: "!!! ---", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Slug(tt.text); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
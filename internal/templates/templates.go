@@ -1,7 +1,9 @@
 package templates
 
 import (
-	"strings"
+	"bytes"
+	"fmt"
+	"text/template"
 	"time"
 )
 
@@ -16,7 +18,12 @@ const (
 )
 
 var templates = map[NoteType]string{
-	Daily: `# {{.Date}}
+	Daily: `---
+type: daily
+created: {{.Date}}
+tags: []
+---
+# {{.Date}}
 
 ## Tasks
 - [ ] 
@@ -27,7 +34,14 @@ var templates = map[NoteType]string{
 ## Follow-ups
 
 `,
-	Project: `# {{.Title}}
+	Project: `---
+type: project
+created: {{.Date}}
+status: active
+project: {{ quote .Title }}
+tags: []
+---
+# {{.Title}}
 
 ## Overview
 
@@ -49,10 +63,21 @@ var templates = map[NoteType]string{
 
 ## Risks
 `,
-	Meeting: `# {{.Title}}
+	Meeting: `{{/* vars
+- name: attendees
+  prompt: Attendees (comma separated)
+*/}}
+---
+type: meeting
+created: {{.Date}}
+attendees: {{ list .Vars.attendees }}
+project: ""
+tags: []
+---
+# {{.Title}}
 
 **Date:** {{.Date}}
-**Attendees:** 
+**Attendees:** {{.Vars.attendees}}
 
 ## Agenda
 
@@ -67,7 +92,14 @@ var templates = map[NoteType]string{
 - [ ] 
 
 ## Follow-up`,
-	Design: `# {{.Title}}
+	Design: `---
+type: design
+created: {{.Date}}
+status: draft
+project: ""
+tags: []
+---
+# {{.Title}}
 
 ## Problem Statement
 
@@ -94,10 +126,20 @@ var templates = map[NoteType]string{
 
 
 ## Risks & Mitigations`,
-	Learning: `# {{.Title}}
+	Learning: `{{/* vars
+- name: source
+  prompt: Source (book, course, article or URL)
+*/}}
+---
+type: learning
+created: {{.Date}}
+source: {{ quote .Vars.source }}
+tags: []
+---
+# {{.Title}}
 
 **Date:** {{.Date}}
-**Source:** 
+**Source:** {{.Vars.source}}
 
 ## Key Concepts
 
@@ -126,18 +168,68 @@ var templates = map[NoteType]string{
 - `,
 }
 
+// fallbackTemplate is used for note types that have no built-in template
+// and no template file in the vault.
+const fallbackTemplate = `---
+type: {{.Type}}
+created: {{.Date}}
+tags: []
+---
+# {{.Title}}
+
+**Date:** {{.Date}}
+
+`
+
+// TemplateData is the value templates are executed against.
 type TemplateData struct {
-	Title string
-	Date  string
+	Title     string
+	Date      string
+	Slug      string
+	Type      string
+	User      string
+	PrevDaily string
+	Now       time.Time
+	Vars      map[string]string
 }
 
-func Render(noteType NoteType, data TemplateData) string {
-	template := templates[noteType]
+// Builtin returns the embedded template for a note type, if there is one.
+func Builtin(noteType NoteType) (string, bool) {
+	source, ok := templates[noteType]
+	return source, ok
+}
+
+// Source returns the embedded template for a note type, or a generic one
+// for types that only exist in the vault config.
+func Source(noteType NoteType) string {
+	if source, ok := Builtin(noteType); ok {
+		return source
+	}
+	return fallbackTemplate
+}
+
+// Render executes a template with text/template and the function library
+// from Funcs. name is used in error messages.
+func Render(name, source string, data TemplateData) (string, error) {
+	_, body, err := SplitVariables(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid variables in template %s: %w", name, err)
+	}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
 	
-	result := strings.ReplaceAll(template, "{{.Title}}", data.Title)
-	result = strings.ReplaceAll(result, "{{.Date}}", data.Date)
+	tmpl, err := template.New(name).Funcs(Funcs(data)).Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
 	
-	return result
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	
+	return buf.String(), nil
 }
 
 func GetTemplateData(title string) TemplateData {
@@ -146,5 +238,8 @@ func GetTemplateData(title string) TemplateData {
 	return TemplateData{
 		Title: title,
 		Date:  now.Format("2006-01-02"),
+		Slug:  Slug(title),
+		Now:   now,
+		Vars:  map[string]string{},
 	}
-}
\ No newline at end of file
+}
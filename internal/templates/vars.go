@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Variable is a value a template asks for when a note is created. Templates
+// declare them in a comment block at the very top:
+//
+//	{{/* vars
+//	- name: source
+//	  prompt: Where did you learn this?
+//	  default: book
+//	  choices: [book, course, article]
+//	*/}}
+type Variable struct {
+	Name    string   `yaml:"name"`
+	Prompt  string   `yaml:"prompt"`
+	Default string   `yaml:"default"`
+	Choices []string `yaml:"choices"`
+}
+
+var varsBlockPattern = regexp.MustCompile(`(?s)\A\s*\{\{-?\s*/\*\s*vars\b(.*?)\*/\s*-?\}\}[ \t]*\r?\n?`)
+
+// SplitVariables separates the variable header from the template body.
+// Templates without a header return no variables and the source unchanged.
+func SplitVariables(source string) ([]Variable, string, error) {
+	match := varsBlockPattern.FindStringSubmatchIndex(source)
+	if match == nil {
+		return nil, source, nil
+	}
+
+	var vars []Variable
+	if err := yaml.Unmarshal([]byte(source[match[2]:match[3]]), &vars); err != nil {
+		return nil, "", err
+	}
+	for i, v := range vars {
+		if v.Name == "" {
+			return nil, "", fmt.Errorf("variable %d has no name", i+1)
+		}
+		if v.Prompt == "" {
+			vars[i].Prompt = v.Name
+		}
+	}
+
+	return vars, source[match[1]:], nil
+}
+
+// Allows reports whether value is acceptable for a variable with choices.
+func (v Variable) Allows(value string) bool {
+	if len(v.Choices) == 0 || value == "" {
+		return true
+	}
+	for _, choice := range v.Choices {
+		if choice == value {
+			return true
+		}
+	}
+	return false
+}
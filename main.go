@@ -11,16 +11,42 @@ import (
 )
 
 func main() {
-	cfg := config.New()
-	service := notes.NewService(cfg)
+	vaultFlag, cliArgs := extractVaultFlag(os.Args[1:])
 
-	if len(os.Args) < 2 {
+	if len(cliArgs) < 1 {
 		showHelp()
 		return
 	}
 
-	command := os.Args[1]
-	args := os.Args[2:]
+	command := cliArgs[0]
+	args := cliArgs[1:]
+
+	// Help doesn't need a working vault, so a broken config can't hide it
+	if isHelpCommand(command) {
+		if len(args) > 0 {
+			showCommandHelp(vaultFlag, args[0])
+		} else {
+			showHelp()
+		}
+		return
+	}
+
+	baseDir, found, err := config.ResolveBaseDir(vaultFlag, command != "init")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating vault: %v\n", err)
+		os.Exit(1)
+	}
+	if !found {
+		fmt.Fprintf(os.Stderr, "⚠ No notes vault found in %s or its parents; using it anyway.\n", baseDir)
+		fmt.Fprintf(os.Stderr, "  Run 'notes init' there, pass --vault <path> or set %s.\n", config.VaultEnv)
+	}
+
+	cfg, err := config.New(baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	service := notes.NewService(cfg)
 
 	switch command {
 	case "init":
@@ -31,25 +57,43 @@ func main() {
 	case "create":
 		if len(args) < 1 {
 			fmt.Fprintf(os.Stderr, "Error: create command requires a note type\n")
-			showCreateHelp()
+			showCreateHelp(cfg)
 			os.Exit(1)
 		}
 		noteType := notes.NoteType(args[0])
-		var title string
-		if len(args) > 1 {
-			title = args[1]
+		title, opts, err := parseCreateArgs(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		if err := service.Create(noteType, title); err != nil {
+		if err := service.Create(noteType, title, opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating note: %v\n", err)
 			os.Exit(1)
 		}
 	case "list":
-		if err := service.List(); err != nil {
+		opts, err := parseListArgs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := service.List(opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error listing notes: %v\n", err)
 			os.Exit(1)
 		}
 	case "tasks":
 		filters := parseTaskFilters(args)
+		if filters.Status != "" {
+			if err := notes.ValidateStatusFilter(filters.Status); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if filters.Priority != "" {
+			if err := notes.ValidatePriorityFilter(filters.Priority); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		if err := service.ShowTasks(filters); err != nil {
 			fmt.Fprintf(os.Stderr, "Error showing tasks: %v\n", err)
 			os.Exit(1)
@@ -70,6 +114,51 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Error saving changes: %v\n", err)
 			os.Exit(1)
 		}
+	case "task":
+		if len(args) < 1 {
+			fmt.Fprintf(os.Stderr, "Error: task command requires a subcommand\n")
+			showTaskHelp()
+			os.Exit(1)
+		}
+		if err := service.HandleTaskCommand(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error with task command: %v\n", err)
+			os.Exit(1)
+		}
+	case "dates":
+		if len(args) < 1 {
+			fmt.Fprintf(os.Stderr, "Error: dates command requires a subcommand\n")
+			showDatesHelp()
+			os.Exit(1)
+		}
+		if err := service.HandleDatesCommand(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error with dates command: %v\n", err)
+			os.Exit(1)
+		}
+	case "tags":
+		if err := service.HandleTagsCommand(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error with tags command: %v\n", err)
+			os.Exit(1)
+		}
+	case "deps":
+		if len(args) < 1 {
+			fmt.Fprintf(os.Stderr, "Error: deps command requires a task ID\n")
+			showDepsHelp()
+			os.Exit(1)
+		}
+		if err := service.ShowDependencies(strings.Join(args, " ")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error showing dependencies: %v\n", err)
+			os.Exit(1)
+		}
+	case "priority":
+		if len(args) < 1 {
+			fmt.Fprintf(os.Stderr, "Error: priority command requires a subcommand\n")
+			showPriorityHelp()
+			os.Exit(1)
+		}
+		if err := service.HandlePriorityCommand(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error with priority command: %v\n", err)
+			os.Exit(1)
+		}
 	case "time":
 		if len(args) < 1 {
 			fmt.Fprintf(os.Stderr, "Error: time command requires a subcommand\n")
@@ -81,7 +170,7 @@ func main() {
 			os.Exit(1)
 		}
 	case "preview":
-		port := 8080
+		port := 0
 		if len(args) > 0 {
 			if p, err := strconv.Atoi(args[0]); err == nil {
 				port = p
@@ -100,9 +189,12 @@ func main() {
 
 		query := ""
 		var tags []string
+		opts := notes.SearchOptions{}
 
 		for _, arg := range args {
-			if strings.HasPrefix(arg, "#") {
+			if arg == "--open" {
+				opts.Open = true
+			} else if strings.HasPrefix(arg, "#") {
 				tags = append(tags, arg)
 			} else if query == "" {
 				query = arg
@@ -111,15 +203,29 @@ func main() {
 			}
 		}
 
-		if err := service.Search(query, tags); err != nil {
+		if err := service.Search(query, tags, opts); err != nil {
 			fmt.Fprintf(os.Stderr, "Error searching notes: %v\n", err)
 			os.Exit(1)
 		}
-	case "help", "-h", "--help":
-		if len(args) > 0 {
-			showCommandHelp(args[0])
+	case "config":
+		if err := handleConfigCommand(cfg, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error with config command: %v\n", err)
+			os.Exit(1)
+		}
+	case "find", "open":
+		query, opts, err := parseFindArgs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if command == "find" {
+			err = service.Find(query, opts)
 		} else {
-			showHelp()
+			err = service.Open(query, opts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error with %s command: %v\n", command, err)
+			os.Exit(1)
 		}
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
@@ -128,6 +234,32 @@ func main() {
 	}
 }
 
+// extractVaultFlag pulls the global --vault option out of the arguments so
+// it can appear before or after the command.
+func extractVaultFlag(args []string) (string, []string) {
+	vault := ""
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--vault" && i+1 < len(args):
+			i++
+			vault = args[i]
+		case strings.HasPrefix(arg, "--vault="):
+			vault = strings.TrimPrefix(arg, "--vault=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	return vault, rest
+}
+
+func isHelpCommand(command string) bool {
+	return command == "help" || command == "-h" || command == "--help"
+}
+
 func showHelp() {
 	fmt.Println(`notes - Organized note-taking with enhanced markdown tasks
 
@@ -140,13 +272,22 @@ QUICK START
 COMMANDS
   init                         Initialize folder structure
   create <type> [title]        Create a new note
-  list                         List existing notes  
+  list [--where key=value]     List existing notes, filtered by frontmatter
+  find [query]                 Fuzzy-find notes by path and title
+  open [query]                 Open the best matching note in your editor
   tasks [options]              Show tasks with filters
+  task <command>               Complete, add, edit or reschedule a task
+  dates normalize              Rewrite relative due dates as absolute dates
+  priority migrate             Turn keyword-guessed priorities into markers
+  deps <task>                  Show what a task depends on and its critical path
+  tags [tag]                   Show the tag tree with note and task counts
+  tags <rename|merge|rm>       Rename, merge or remove tags across the vault
   status                       Show changed notes and todos
   time <command>               Time tracking (start/stop/status)
   search <query> [#tags]       Search notes by content/tags
   preview [port]               Start markdown preview server (default: 8080)
   save [message]               Commit changes to git
+  config <command>             Show or change vault settings (get/set/list)
 
 GETTING STARTED
   1. Run 'notes init' to set up folders
@@ -158,20 +299,38 @@ GETTING STARTED
 HELP TOPICS
   notes help create            # Note types and creation
   notes help tasks             # Task views and filters
+  notes help task              # Changing tasks from the command line
   notes help time              # Time tracking system
   notes help markdown          # Enhanced markdown syntax
   notes help search            # Search and filtering
+  notes help config            # Vault configuration
+
+VAULT LOCATION
+  notes looks for a .notes/ folder in the current directory and its
+  parents, so commands work from any subfolder of your vault.
+  --vault <path>               Use a specific vault
+  NOTES_DIR=<path>             Default vault when run from elsewhere
 
 TIP: All files are standard markdown - learn basics at:
      https://www.markdownguide.org/basic-syntax/`)
 }
 
-func showCommandHelp(command string) {
+func showCommandHelp(vaultFlag, command string) {
 	switch command {
 	case "create":
-		showCreateHelp()
+		showCreateHelp(helpConfig(vaultFlag))
 	case "tasks":
 		showTasksHelp()
+	case "task":
+		showTaskHelp()
+	case "dates":
+		showDatesHelp()
+	case "priority":
+		showPriorityHelp()
+	case "deps":
+		showDepsHelp()
+	case "tags":
+		showTagsHelp()
 	case "time":
 		showTimeHelp()
 	case "search":
@@ -180,41 +339,100 @@ func showCommandHelp(command string) {
 		showMarkdownHelp()
 	case "preview":
 		showPreviewHelp()
+	case "config":
+		showConfigHelp()
+	case "find", "open":
+		showFindHelp()
 	default:
 		fmt.Printf("No detailed help available for '%s'\n", command)
-		fmt.Println("Available help topics: create, tasks, time, search, markdown, preview")
+		fmt.Println("Available help topics: create, tasks, time, search, markdown, preview, config, find")
 	}
 }
 
-func showCreateHelp() {
-	fmt.Println(`Usage: notes create <type> [title]
+// helpConfig loads the config for help that lists configured settings.
+// Help has to work in a vault whose config is broken too, so it falls back
+// to the defaults and says why.
+func helpConfig(vaultFlag string) *config.Config {
+	baseDir, _, err := config.ResolveBaseDir(vaultFlag, true)
+	if err == nil {
+		var cfg *config.Config
+		if cfg, err = config.New(baseDir); err == nil {
+			return cfg
+		}
+	}
+	fmt.Fprintf(os.Stderr, "⚠ Showing the defaults; the vault config could not be loaded:\n%v\n\n", err)
+	return config.Default()
+}
 
-Available types:
-  daily    - Daily notes (auto-dated)
-  project  - Project documentation  
-  meeting  - Meeting notes
-  design   - Technical design documents
-  learning - Learning notes and tutorials
+func showCreateHelp(cfg *config.Config) {
+	fmt.Println("Usage: notes create <type> [title] [--var name=value ...] [--no-edit]")
+	fmt.Println()
+	fmt.Println("The new note opens in your editor: the editor setting, then $VISUAL,")
+	fmt.Println("then $EDITOR. Pass --no-edit to only create the file.")
+	fmt.Println()
+	fmt.Println("Available types:")
+	for _, nt := range cfg.NoteTypes {
+		description := nt.Description
+		if description == "" {
+			description = "Notes in " + nt.Dir + "/"
+		}
+		if nt.TitleRequired {
+			description += " (title required)"
+		}
+		fmt.Printf("  %-9s- %s\n", nt.Name, description)
+	}
+	fmt.Println()
+	fmt.Println("Add your own types under note_types in .notes/config.yaml.")
+	fmt.Println()
+	fmt.Println(`Templates:
+  Notes are rendered from templates/<type>.md when it exists, otherwise
+  from the built-in template. Templates use Go text/template syntax:
+    {{.Title}} {{.Date}} {{.Type}}   Note title, today's date, note type
+    {{ dateAdd 1 }}                  Tomorrow (YYYY-MM-DD)
+    {{ dateAdd -1 "Mon Jan 2" }}     Yesterday with a custom layout
+    {{ date "Monday" }}              Today with a custom layout
+    {{ week }}                       ISO week number
+    {{ prevDaily }}                  Path of the previous daily note
+    {{ user }}                       user.name setting or git user.name
+    {{ slug .Title }}                kebab-case text
 
-Examples:
-  notes create daily
-  notes create project "My New Project"
-  notes create meeting "Team Standup"`)
+Template variables:
+  Templates can ask for extra values in a header block:
+    {{/* vars
+    - name: attendees
+      prompt: Attendees (comma separated)
+      default: ""
+      choices: []
+    */}}
+  Use them as {{.Vars.attendees}}. notes create prompts for each one on a
+  terminal; pass --var attendees="alice, bob" to skip the prompt.`)
+	fmt.Println()
+	fmt.Println("Examples:")
+	for _, nt := range cfg.NoteTypes {
+		if nt.TitleRequired {
+			fmt.Printf("  notes create %s \"My %s\"\n", nt.Name, strings.Title(nt.Name))
+		} else {
+			fmt.Printf("  notes create %s\n", nt.Name)
+		}
+	}
 }
 
 func showSearchHelp() {
-	fmt.Println(`Usage: notes search <query> [#tag ...]
+	fmt.Println(`Usage: notes search <query> [#tag ...] [--open]
 
 Search for notes by content and/or tags:
   - Search by text content in any note
   - Filter by tags using #tagname
   - Combine text search with tag filtering
+  - --open opens the top hit in your editor at the matching line
 
 Examples:
   notes search "API design"          # Search for "API design" text
   notes search "" #work              # Find all notes with #work tag
   notes search "meeting" #urgent     # Find "meeting" text with #urgent tag
-  notes search #project #active      # Find notes with both tags`)
+  notes search #project #active      # Find notes with both tags
+  notes search "" #work              # Also finds #work/backend and other nested tags
+  notes search "retry logic" --open  # Jump straight to the first match`)
 }
 
 func showTasksHelp() {
@@ -228,24 +446,215 @@ BASIC VIEWS
   notes tasks --all            # Override defaults, show everything
 
 FILTERS
-  --tag <tag>       Filter by tag (--tag urgent)
-  --priority <pri>  Filter by priority (high, medium, low)  
+  --status <state>  Filter by status (default: open = todo + in-progress)
+                    todo, in-progress, done, cancelled, deferred, open, all
+  --done            Shorthand for --status done
+  --tag <tag>       Filter by tag (--tag urgent); --tag work matches #work/backend
+  --priority <pri>  Filter by priority (high, medium, low; see notes help priority)
   --overdue         Show only overdue tasks
   --today           Show only tasks due today
   --file <pattern>  Filter by file pattern (--file daily/)
   --sort <method>   Sort by priority, due, or file
+  --with-parents    Also show the parent tasks of matching subtasks
+  --waiting         Show tasks waiting on someone, grouped by person
+  --ready           Show only tasks that nothing is blocking
+  --mine            Show only tasks assigned to you (@you)
+  --assignee <name> Show only tasks assigned to someone (--assignee bob)
+  --upcoming        Include tasks whose start or scheduled date is later
 
 EXAMPLES
   notes tasks --summary                    # Quick overview
   notes tasks --tag urgent --overdue      # Urgent overdue tasks
   notes tasks --file daily/ --today       # Today's daily tasks
   notes tasks --priority high --sort due  # High priority by due date
+  notes tasks --status in-progress        # What's underway
+  notes tasks --status done --file projects/  # Finished project tasks
+  notes tasks --waiting                   # Who am I waiting on?
+  notes tasks --ready --sort priority     # What can I pick up next?
+  notes tasks --mine --focus              # My overdue and today's tasks
 
 TASK DISPLAY
   Tasks show time tracking progress and estimates:
   ├─ 🔴 Fix auth bug [1h30m/2h] ~2h (L45)    # Progress vs estimate
   ├─ 🟡 Add tests [45m worked] ~1h (L67)     # Time worked so far  
-  └─ ⚪ Update docs [2h completed] ~1h30m     # Over estimate, done`)
+  └─ ⚪ Update docs [2h completed] ~1h30m     # Over estimate, done
+
+SUBTASKS
+  Indented tasks are subtasks of the task above them. Parents show how
+  many subtasks are done, and their worked time and estimate include
+  their subtasks':
+  ├─ ⚪ Launch site [1/3] [2h/6h] ~6h (L10)
+    └─ ⚪ Write copy ~2h (L12)
+
+START, SCHEDULED AND WAITING
+  start:<date>        Hidden until this date (use --upcoming to see it)
+  scheduled:<date>    Hidden until this date, then shown in --focus/--today
+  waiting:@person     Blocked on someone; listed by notes tasks --waiting
+  - [ ] Renew passport start:2025-03-01 due:2025-04-01
+  - [ ] Review contract waiting:@legal`)
+}
+
+func showTaskHelp() {
+	fmt.Println(`notes task - Change tasks without opening the file
+
+COMMANDS
+  done <task>              Mark a task done (stops its timer and logs the time;
+                           recurring tasks get their next occurrence)
+  add <text> [options]     Add a new task to a note
+  edit <task> [options]    Change a task, or open it in your editor
+  due <task> <date>        Set or clear a task's due date
+
+  <task> is a partial text match or a task ID (^t-3f9a01c2). Text matching
+  several tasks is refused unless it is one task's whole text.
+  Lines are rewritten in place; indentation and time logs are kept.
+
+ADD OPTIONS
+  --to <note>       daily (default), a note path, or a fuzzy note query.
+                    Paths must be .md/.txt notes in the note folders.
+  --due <date>      Due date
+  --est <duration>  Estimate (30m, 2h, 1h30m)
+  --tag <tag>       Tag, may repeat
+  --priority <pri>  high, medium or low (written as !1, !2, !3)
+  --start <date>    Hide the task until this date
+  --scheduled <date>  Plan the task for this date
+  --waiting <person>  Mark the task as waiting on someone
+
+  New tasks fill an empty "- [ ]" placeholder, or go at the end of the
+  note's Tasks/Actions section, or at the end of the note.
+
+EDIT OPTIONS
+  --text <text>     Replace the description, keeping due:, est:, every:, tags,
+                    @assignees and ID
+  --due <date>      Set the due date (none clears it)
+  --est <duration>  Set the estimate (none clears it)
+  --tag <tag>       Add a tag
+  --untag <tag>     Remove a tag
+  --priority <pri>  Set the priority (none removes the marker)
+  --start <date>, --scheduled <date>, --waiting <person>
+                    Set these like --due (none clears them)
+  With no options, the note is opened in your editor at the task's line.
+
+DATES
+  2024-12-31, 2024-12-31T15:00, today, tomorrow, fri, +2d, +1w, eow, eom,
+  with an optional time (tomorrow@15:00, fri@9am), or none.
+  Dates given on the command line are always written as absolute dates.
+
+EXAMPLES
+  notes task add "Review PR #42" --due tomorrow --est 30m --tag review
+  notes task add "Draft agenda" --to projects/launch
+  notes task done "Review PR"
+  notes task due "Draft agenda" +2d
+  notes task edit ^t-3f9a01c2 --text "Draft the launch agenda" --untag review
+  notes task edit "Sign contract" --waiting legal`)
+}
+
+func showDatesHelp() {
+	fmt.Println(`notes dates - Work with dates in tasks
+
+COMMANDS
+  normalize [--dry-run]   Rewrite relative due:, start: and scheduled: dates
+                          as absolute dates
+
+DATE FORMATS
+  due:2024-12-31          A date
+  due:2024-12-31T15:00    A date and time of day
+  due:today, due:tomorrow, due:yesterday
+  due:fri, due:friday     The next Friday (today if it is Friday)
+  due:+3d, due:+2w, due:+1m, due:+1y, due:-1d
+  due:eod                 Today at 17:00
+  due:eow, due:eom, due:eoy   End of week (Sunday), month or year
+  due:tomorrow@15:00, due:fri@9am   Any of the above with a time
+
+  Relative dates are read relative to the day you view them, so
+  due:tomorrow never arrives. Run 'notes dates normalize' after writing
+  them to pin them to real dates. Tasks with a due time count as overdue
+  as soon as that time has passed.
+
+EXAMPLES
+  notes dates normalize --dry-run   # Show what would change
+  notes dates normalize             # Rewrite the files`)
+}
+
+func showDepsHelp() {
+	fmt.Println(`notes deps - Task dependencies
+
+SYNTAX
+  - [ ] Build release id:build est:2h
+  - [ ] Write release notes id:notes est:1h
+  - [ ] Publish release after:build,notes
+  - [ ] Fix flaky tests est:3h blocks:build
+
+  id:<name>         Names a task (a ^id anchor works too)
+  after:<ids>       This task waits until those tasks are checked off
+  blocks:<ids>      Those tasks wait until this one is checked off
+
+  Tasks with unfinished dependencies are blocked: task views show what
+  they wait for, and 'notes tasks --ready' leaves them out. Cancelled
+  tasks don't block anything. Dependency cycles are reported as errors.
+
+USAGE
+  notes deps <id|task>    Dependency tree, the tasks it unblocks, and the
+                          critical path: the chain of unfinished tasks with
+                          the most est: time left before it can be done
+
+EXAMPLES
+  notes deps publish
+  notes deps ^t-3f9a01c2
+  notes tasks --ready`)
+}
+
+func showTagsHelp() {
+	fmt.Println(`notes tags - See and reorganize tags
+
+COMMANDS
+  [tag]                         Tag tree with note and open task counts,
+                                optionally just the branch under tag
+  rename <old> <new>            Rename a tag
+  merge <tag>... --into <tag>   Replace several tags with one
+  rm <tag>                      Remove a tag
+
+  Changes apply to the tag and every tag nested below it, so renaming
+  work to job turns #work/backend into #job/backend. Task lines, note text
+  and frontmatter tags are rewritten; code blocks and inline code are left
+  alone. A tag that would end up twice on a line is kept once.
+
+  The changes are shown as a diff and applied after you confirm. With
+  git.auto_commit on, the rewritten notes are committed together.
+
+OPTIONS
+  --dry-run, -n   Only show what would change
+  --yes, -y       Apply without asking (needed when not run in a terminal)
+
+EXAMPLES
+  notes tags work
+  notes tags rename backend platform --dry-run
+  notes tags merge bug bugs --into bugfix
+  notes tags rm wip --yes`)
+}
+
+func showPriorityHelp() {
+	fmt.Println(`notes priority - Task priorities
+
+MARKERS
+  - [ ] Fix login bug !1        High priority (also p:high or (A) at the start)
+  - [ ] Update docs !2          Medium priority (also p:medium or (B))
+  - [ ] Tidy scripts !3         Low priority (also p:low or (C))
+  Tasks without a marker are low priority.
+
+KEYWORDS
+  Vaults can opt in to guessing priority from words in the task text
+  (priority.high and priority.medium) for tasks without a marker:
+  notes config set priority.infer_keywords true
+
+COMMANDS
+  migrate [--dry-run]   Write the keyword-guessed priority of every unfinished
+                        task as a marker (!1 or !2), so nothing changes when
+                        keyword guessing is off
+
+EXAMPLES
+  notes priority migrate --dry-run   # Show what would change
+  notes priority migrate             # Rewrite the files
+  notes tasks --priority high --sort priority`)
 }
 
 func showMarkdownHelp() {
@@ -256,45 +665,112 @@ BASIC TASKS
   - [ ] Basic task
   - [x] Completed task
 
+  Other checkbox states are understood too:
+  - [/] In progress     (shown by default with open tasks)
+  - [>] Deferred        (hidden unless --status deferred or all)
+  - [-] Cancelled       (hidden unless --status cancelled or all)
+
 ENHANCED SYNTAX
   Add due dates, estimates, tags, and priority:
 
-  Due dates:     - [ ] Task due:2024-12-31
+  Due dates:     - [ ] Task due:2024-12-31 (or due:2024-12-31T15:00, due:fri)
   Estimates:     - [ ] Task est:2h #urgent  
   Tags:          - [ ] Task #urgent #work #backend
-  Priority:      - [ ] URGENT task !!! (keywords: urgent, critical, important)
+  Priority:      - [ ] Task !1 (or p:high, or (A) Task; !2 medium, !3 low)
+  Recurrence:    - [ ] Submit timesheet due:2024-12-06 every:weekly
+  Start/plan:    - [ ] Task start:2025-03-01 scheduled:mon
+  Waiting:       - [ ] Task waiting:@alice
+  Dependencies:  - [ ] Deploy id:deploy after:build,tests (see notes help deps)
+  Assignees:     - [ ] Review PR @bob @alice
+
+ASSIGNEES
+  @name assigns a task to someone; notes tasks --mine and --assignee <name>
+  filter on it. You are user.handle, or user.name (else git user.name):
+  @alice matches "Alice Smith" unless another Alice is assigned somewhere.
+  In meeting notes, an action item that starts with an attendee's name is
+  theirs:
+  - [ ] Bob: send the deck          (with attendees: [Alice, Bob])
+
+RECURRING TASKS
+  every:daily, every:weekly, every:monthly, every:yearly, every:weekdays
+  every:mon,thu       on those days of the week
+  every:2w            every N days (d), weeks (w), months (m) or years (y)
+  every:monthly:15    on day 15 of each month (clamped to the month's end)
+
+  Completing a recurring task (notes task done, or a checkbox in the
+  preview) adds an unchecked copy below it with the next due date. The next
+  date follows the schedule from the old due date, skipping any dates that
+  have already passed. start: and scheduled: move by as many days; the
+  copy has no ID and no after:/blocks: dependencies.
 
 TIME TRACKING INTEGRATION
   When you track time, structured logs are automatically added:
   
-  - [ ] Fix auth bug est:2h #backend
+  - [ ] Fix auth bug est:3h #backend
     Time log:
     • 2024-01-15 09:30-10:45 (1h15m) - Work session
     • 2024-01-15 14:00-15:30 (1h30m) - Testing fixes
     Remaining: ~15m
 
 PRIORITY KEYWORDS
-  Use these words in task text for automatic priority detection:
-  - urgent, critical, important = High priority
-  - Use !!! for emphasis
+  Tasks without a marker are low priority. Guessing priority from words in
+  the task text is off unless the vault turns it on:
+    notes config set priority.infer_keywords true
+  Then priority.high words (urgent, asap, critical, important, !!!) mean
+  high and priority.medium words (!!, soon, priority) mean medium.
+  'notes priority migrate' writes those guesses as !1/!2 markers instead.
+
+FRONTMATTER
+  Notes can start with a YAML block describing the whole note:
+  ---
+  type: project
+  status: active
+  tags: [work, backend]
+  ---
+  Note tags apply to every task in the note. Filter notes with
+  'notes list --where status=active' (also key!=value, or just key).
 
 TAG SYSTEM
   Tags help organize and filter tasks:
   - Use #tagname anywhere in task text
   - Multiple tags: #work #urgent #backend
-  - Filter tasks: notes tasks --tag urgent`)
+  - Any language and dashes: #café #v2-launch
+  - Nest tags with /: #work/backend; --tag work also matches #work/backend
+  - Numbers (#42) and tags inside code are not tags
+  - Filter tasks: notes tasks --tag urgent
+  - See every tag: notes tags
+  - Rename, merge or remove tags: notes help tags`)
 }
 
 func showTimeHelp() {
 	fmt.Println(`notes time - Time tracking for markdown tasks
 
 COMMANDS
-  start <task>     Find task by partial text match and start timer
+  start <task>     Find task by partial text match or ^id and start timer
+                   (-m <message> describes the session up front)
   pause            Pause current active timer  
   resume           Resume paused timer
   stop             Stop timer and log time to markdown
+                   (-m <message> describes the session)
+  amend -m <message>  Change the description of the last logged entry
+  entries <task>   List a task's time entries with their numbers (done and
+                   cancelled tasks too, as do edit, split and rm)
+  edit <task> <n>  Change entry n: --date <day>, --start HH:MM, --end HH:MM,
+                   -m <message>. The duration follows the new range. To end
+                   past midnight, give the day: --end "2024-01-16 00:45"
+  split <task> <n> <HH:MM>  Cut entry n in two (-m describes the second half)
+  rm <task> <n>    Delete entry n (the whole time log if it was the last)
+  log <task> <time>  Log time worked without a timer. <time> is a duration
+                   (1h30m, ending now) or a range (14:00-15:30)
+                   --at HH:MM     when a duration started
+                   --date <day>   the day worked (YYYY-MM-DD, yesterday)
+                   -m <message>   the entry's description
   status           Show current timer status
   report [period]  Show time report (today, week, month)
+                   --mine or --assignee <name> for one person's tasks
+                   --csv for one row per session, for invoices
+  recalc           Rewrite every Remaining:/Total: line from the time logs
+                   (--dry-run to preview)
 
 WORKFLOW
   1. Create task in markdown: - [ ] Fix auth bug est:2h #urgent
@@ -303,19 +779,44 @@ WORKFLOW
   4. Stop timer: notes time stop
   5. Time is automatically logged to your markdown file
 
+  Each entry says what you did: pass -m to start or stop, or answer the
+  question stop asks in a terminal (Enter logs "Work session").
+
+  Forgot the timer? Log the time afterwards:
+    notes time log "Fix auth" 1h30m --at 09:00 --date 2024-05-02 -m "code review"
+    notes time log "Fix auth" 14:00-15:30
+  Entries that overlap logged time or the running timer are refused.
+
+TASK IDS
+  Starting a timer adds a block ID to the task (- [ ] Fix auth bug ^t-3f9a01c2).
+  The time log is written to the task with that ID when the timer stops,
+  even if lines were added or moved in the meantime. If the task is gone,
+  nothing is logged and the timer state is kept.
+
 TIME LOGS
   Time tracking adds structured logs to your tasks:
   
-  - [ ] Fix auth bug est:2h #urgent
+  - [ ] Fix auth bug est:3h #urgent
     Time log:
     • 2024-01-15 09:30-10:45 (1h15m) - Work session
     • 2024-01-15 14:00-15:30 (1h30m) - Testing fixes
     Remaining: ~15m
 
+  The Remaining: line (or Total: when the task has no estimate) is
+  updated whenever time is logged. Completing the task turns it into
+  Total: 2h45m (under estimate by 15m).
+
+  Sessions past midnight are logged as one entry per day. Entries may
+  also span days, give seconds or carry a UTC offset:
+    • 2024-01-15 23:30 → 2024-01-16 00:45 (1h15m) - Release night
+    • 2024-01-16 09:00:15+01:00-09:30:45+01:00 (30m30s) - Standup
+  notes config set time.precise true makes the timer write them that way.
+
 REPORTS
   notes time report         # Today (default)
   notes time report week    # This week's summary
   notes time report month   # This month's summary
+  notes time report week --assignee bob   # Time on Bob's tasks
 
 EXAMPLES  
   notes time start "Fix login bug"
@@ -330,14 +831,15 @@ func showPreviewHelp() {
 	fmt.Println(`notes preview - Markdown preview server with Mermaid diagrams
 
 USAGE
-  notes preview [port]         # Start server on specified port (default: 8080)
+  notes preview [port]         # Start server on specified port (default: preview.port)
 
 FEATURES
   - Live markdown rendering with GitHub-flavored styling
   - Automatic Mermaid diagram rendering from code blocks
   - File browser to navigate all your notes
   - Responsive design for mobile viewing
-  - Task list rendering with checkboxes
+  - Task list rendering with checkboxes; clicking one completes or
+    reopens the task in the markdown file
 
 MERMAID DIAGRAMS
   Create diagrams using standard mermaid syntax in code blocks:
@@ -361,6 +863,186 @@ The server will display a file browser at the root and render any .md file
 when clicked. The preview updates automatically when you save changes to files.`)
 }
 
+func showFindHelp() {
+	fmt.Println(`notes find / notes open - Jump to a note by name
+
+USAGE
+  notes find [query] [--print] [--limit N]
+  notes open [query] [--print]
+
+Queries match fuzzily against each note's path and title (the frontmatter
+title or first "# " heading), so "mtgplan" finds meetings/...-planning.md.
+Results are ranked by match quality, then by the most recent git commit
+or file change.
+
+  find       List matching notes, best first
+  open       Open the best match; on a terminal with several matches,
+             pick one from a numbered list
+  --print    Print paths instead of opening or decorating (for scripts)
+  --limit N  Show at most N results from find (default 20)
+
+EXAMPLES
+  notes open standup                 # Pick among standup meetings
+  notes find auth --print            # Paths only
+  vim "$(notes open auth --print)"   # Use with other tools`)
+}
+
+func showConfigHelp() {
+	fmt.Println(`notes config - Vault settings
+
+COMMANDS
+  list                   Show every setting and its current value
+  get <key>              Show a single setting
+  set <key> <value>      Change a setting in the vault config
+  set --global <k> <v>   Change a setting in your user config
+
+FILES
+  .notes/config.yaml                 Vault config, meant to be checked in
+  $XDG_CONFIG_HOME/notes/config.yaml User defaults (~/.config/notes)
+
+  Vault settings override user settings, which override built-in defaults.
+  List values can be given comma-separated. set checks the settings that
+  result from both files and writes nothing if they are invalid.
+
+EXAMPLES
+  notes config list
+  notes config get preview.port
+  notes config set preview.port 3000
+  notes config set search_dirs daily,projects,meetings
+  notes config set --global editor "code -w"`)
+}
+
+func handleConfigCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		showConfigHelp()
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		settings, err := cfg.List()
+		if err != nil {
+			return err
+		}
+		for _, setting := range settings {
+			fmt.Printf("%s = %s\n", setting.Key, setting.Value)
+		}
+		return nil
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: notes config get <key>")
+		}
+		value, err := cfg.Get(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	case "set":
+		path := cfg.VaultConfigPath()
+		setArgs := args[1:]
+		if len(setArgs) > 0 && setArgs[0] == "--global" {
+			path = config.UserConfigPath()
+			setArgs = setArgs[1:]
+		}
+		if len(setArgs) < 2 {
+			return fmt.Errorf("usage: notes config set [--global] <key> <value>")
+		}
+		key := setArgs[0]
+		value := strings.Join(setArgs[1:], " ")
+		if err := cfg.Set(path, key, value); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Set %s = %s\n", key, value)
+		return nil
+	default:
+		return fmt.Errorf("unknown config command: %s", args[0])
+	}
+}
+
+func parseCreateArgs(args []string) (string, notes.CreateOptions, error) {
+	opts := notes.CreateOptions{Vars: map[string]string{}}
+	var titleParts []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--var" || strings.HasPrefix(arg, "--var="):
+			assignment := strings.TrimPrefix(arg, "--var=")
+			if arg == "--var" {
+				if i+1 >= len(args) {
+					return "", opts, fmt.Errorf("--var requires name=value")
+				}
+				i++
+				assignment = args[i]
+			}
+			name, value, ok := strings.Cut(assignment, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return "", opts, fmt.Errorf("invalid --var %q, expected name=value", assignment)
+			}
+			opts.Vars[strings.TrimSpace(name)] = value
+		case arg == "--no-edit":
+			opts.NoEdit = true
+		default:
+			titleParts = append(titleParts, arg)
+		}
+	}
+
+	return strings.Join(titleParts, " "), opts, nil
+}
+
+func parseFindArgs(args []string) (string, notes.FindOptions, error) {
+	opts := notes.FindOptions{}
+	var queryParts []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch arg {
+		case "--print":
+			opts.Print = true
+		case "--limit":
+			if i+1 >= len(args) {
+				return "", opts, fmt.Errorf("--limit requires a number")
+			}
+			i++
+			limit, err := strconv.Atoi(args[i])
+			if err != nil || limit < 1 {
+				return "", opts, fmt.Errorf("invalid --limit: %s", args[i])
+			}
+			opts.Limit = limit
+		default:
+			queryParts = append(queryParts, arg)
+		}
+	}
+
+	return strings.Join(queryParts, " "), opts, nil
+}
+
+func parseListArgs(args []string) (notes.ListOptions, error) {
+	opts := notes.ListOptions{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--where":
+			if i+1 >= len(args) {
+				return opts, fmt.Errorf("--where requires key=value")
+			}
+			i++
+			opts.Where = append(opts.Where, args[i])
+		case strings.HasPrefix(arg, "--where="):
+			opts.Where = append(opts.Where, strings.TrimPrefix(arg, "--where="))
+		default:
+			return opts, fmt.Errorf("unknown list option: %s", arg)
+		}
+	}
+
+	return opts, nil
+}
+
 func parseTaskFilters(args []string) notes.TaskFilters {
 	filters := notes.TaskFilters{}
 
@@ -382,6 +1064,13 @@ func parseTaskFilters(args []string) notes.TaskFilters {
 				i++
 				filters.Priority = args[i]
 			}
+		case "--status":
+			if i+1 < len(args) {
+				i++
+				filters.Status = args[i]
+			}
+		case "--done":
+			filters.Status = "done"
 		case "--overdue":
 			filters.Overdue = true
 		case "--today":
@@ -394,6 +1083,21 @@ func parseTaskFilters(args []string) notes.TaskFilters {
 			filters.Summary = true
 		case "--full":
 			filters.Full = true
+		case "--with-parents":
+			filters.WithParents = true
+		case "--waiting":
+			filters.Waiting = true
+		case "--ready":
+			filters.Ready = true
+		case "--mine":
+			filters.Mine = true
+		case "--assignee":
+			if i+1 < len(args) {
+				i++
+				filters.Assignee = strings.TrimPrefix(args[i], "@")
+			}
+		case "--upcoming":
+			filters.Upcoming = true
 		case "--file":
 			if i+1 < len(args) {
 				i++